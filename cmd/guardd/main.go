@@ -28,6 +28,7 @@ func main() {
 	log.Info("http server listening", zap.String("addr", server.Addr()))
 
 	w := watcher.New(cfg, log, reg)
+	server.Handle("/intents", httpserver.JSON(func() interface{} { return w.Intents() }))
 	watcherErr := make(chan error, 1)
 	go func() {
 		watcherErr <- w.Run(ctx)
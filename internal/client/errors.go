@@ -0,0 +1,152 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TreasuryGuard require strings.
+var (
+	ErrTokenNotAllowed       = errors.New("TOKEN_NOT_ALLOWED")
+	ErrAmountExceedsCap      = errors.New("AMOUNT_EXCEEDS_CAP")
+	ErrNotPending            = errors.New("NOT_PENDING")
+	ErrRequestExpired        = errors.New("REQUEST_EXPIRED")
+	ErrAlreadyApproved       = errors.New("ALREADY_APPROVED")
+	ErrInsufficientApprovals = errors.New("INSUFFICIENT_APPROVALS")
+	ErrDelayNotMet           = errors.New("DELAY_NOT_MET")
+	ErrInsufficientBalance   = errors.New("INSUFFICIENT_BALANCE")
+	ErrInvalidID             = errors.New("INVALID_ID")
+	ErrPaused                = errors.New("contract is paused")
+	ErrUnauthorized          = errors.New("account is missing the required role")
+	ErrReverted              = errors.New("execution reverted")
+)
+
+// Node-side rejections of a transaction.
+var (
+	ErrUnderpriced       = errors.New("transaction underpriced")
+	ErrNonceTooLow       = errors.New("nonce too low")
+	ErrNonceTooHigh      = errors.New("nonce too high")
+	ErrAlreadyKnown      = errors.New("transaction already known")
+	ErrInsufficientFunds = errors.New("insufficient funds for gas")
+)
+
+var revertReasons = map[string]error{
+	"TOKEN_NOT_ALLOWED":      ErrTokenNotAllowed,
+	"AMOUNT_EXCEEDS_CAP":     ErrAmountExceedsCap,
+	"NOT_PENDING":            ErrNotPending,
+	"REQUEST_EXPIRED":        ErrRequestExpired,
+	"ALREADY_APPROVED":       ErrAlreadyApproved,
+	"INSUFFICIENT_APPROVALS": ErrInsufficientApprovals,
+	"DELAY_NOT_MET":          ErrDelayNotMet,
+	"INSUFFICIENT_BALANCE":   ErrInsufficientBalance,
+	"INVALID_ID":             ErrInvalidID,
+	"Pausable: paused":       ErrPaused,
+}
+
+// customErrors maps OpenZeppelin v5 custom error selectors to sentinels.
+var customErrors = map[[4]byte]struct {
+	sig string
+	err error
+}{
+	selector("EnforcedPause()"):                                   {"EnforcedPause()", ErrPaused},
+	selector("AccessControlUnauthorizedAccount(address,bytes32)"): {"AccessControlUnauthorizedAccount(address,bytes32)", ErrUnauthorized},
+	selector("AccessControlBadConfirmation()"):                    {"AccessControlBadConfirmation()", ErrUnauthorized},
+}
+
+var nodeErrors = []struct {
+	match string
+	err   error
+}{
+	{"nonce too low", ErrNonceTooLow},
+	{"nonce too high", ErrNonceTooHigh},
+	{"already known", ErrAlreadyKnown},
+	{"replacement transaction underpriced", ErrUnderpriced},
+	{"transaction underpriced", ErrUnderpriced},
+	{"max fee per gas less than block base fee", ErrUnderpriced},
+	{"insufficient funds", ErrInsufficientFunds},
+}
+
+func selector(sig string) [4]byte {
+	var out [4]byte
+	copy(out[:], crypto.Keccak256([]byte(sig))[:4])
+	return out
+}
+
+// RevertError is a decoded contract revert. It unwraps to the matching
+// sentinel, or ErrReverted when the reason is not one the daemon knows.
+type RevertError struct {
+	Reason string
+	Data   []byte
+	Err    error
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.Reason
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.Err
+}
+
+// decodeRevert turns revert data into a RevertError.
+func decodeRevert(data []byte) *RevertError {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return revertFromReason(reason, data)
+	}
+	if len(data) >= 4 {
+		var sel [4]byte
+		copy(sel[:], data[:4])
+		if known, ok := customErrors[sel]; ok {
+			reason := strings.TrimSuffix(known.sig, "()")
+			if known.err == ErrUnauthorized && len(data) >= 4+64 {
+				account := common.BytesToAddress(data[4:36])
+				role := common.BytesToHash(data[36:68])
+				reason = fmt.Sprintf("AccessControlUnauthorizedAccount(%s, %s)", account.Hex(), RoleName(role))
+			}
+			return &RevertError{Reason: reason, Data: data, Err: known.err}
+		}
+		return &RevertError{Reason: hexutil.Encode(data[:4]), Data: data, Err: ErrReverted}
+	}
+	return &RevertError{Data: data, Err: ErrReverted}
+}
+
+func revertFromReason(reason string, data []byte) *RevertError {
+	if sentinel, ok := revertReasons[reason]; ok {
+		return &RevertError{Reason: reason, Data: data, Err: sentinel}
+	}
+	return &RevertError{Reason: reason, Data: data, Err: ErrReverted}
+}
+
+// classify wraps err with the matching sentinel so callers can use
+// errors.Is. Errors it does not recognise are returned unchanged.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var revert *RevertError
+	if errors.As(err, &revert) {
+		return err
+	}
+	if revert, ok := revertFromError(err); ok {
+		return revert
+	}
+	msg := strings.ToLower(err.Error())
+	for _, known := range nodeErrors {
+		if errors.Is(err, known.err) {
+			return err
+		}
+		if strings.Contains(msg, known.match) {
+			return fmt.Errorf("%w: %v", known.err, err)
+		}
+	}
+	return err
+}
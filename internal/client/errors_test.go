@@ -0,0 +1,54 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestClassifyErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nonceTooLow", errors.New("nonce too low: next nonce 5, tx nonce 4"), ErrNonceTooLow},
+		{"replacementUnderpriced", errors.New("replacement transaction underpriced"), ErrUnderpriced},
+		{"baseFee", errors.New("max fee per gas less than block base fee"), ErrUnderpriced},
+		{"alreadyKnown", errors.New("already known"), ErrAlreadyKnown},
+		{"funds", errors.New("insufficient funds for gas * price + value"), ErrInsufficientFunds},
+		{"revertMessage", errors.New("execution reverted: DELAY_NOT_MET"), ErrDelayNotMet},
+		{"legacyPause", errors.New("execution reverted: Pausable: paused"), ErrPaused},
+		{"unknownRevert", errors.New("execution reverted: SOMETHING"), ErrReverted},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classify(tc.err); !errors.Is(got, tc.want) {
+				t.Fatalf("classify(%q) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+
+	plain := errors.New("connection refused")
+	if got := classify(plain); got != plain {
+		t.Fatalf("expected unknown errors to pass through, got %v", got)
+	}
+}
+
+func TestDecodeUnauthorizedRevert(t *testing.T) {
+	account := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	data := append([]byte{}, crypto.Keccak256([]byte("AccessControlUnauthorizedAccount(address,bytes32)"))[:4]...)
+	data = append(data, common.LeftPadBytes(account.Bytes(), 32)...)
+	data = append(data, GuardianRole.Bytes()...)
+
+	revert := decodeRevert(data)
+	if !errors.Is(revert, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", revert.Err)
+	}
+	want := "AccessControlUnauthorizedAccount(" + account.Hex() + ", GUARDIAN_ROLE)"
+	if revert.Reason != want {
+		t.Fatalf("got reason %q want %q", revert.Reason, want)
+	}
+}
@@ -2,34 +2,58 @@ package client
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"base-treasury-guard/internal/config"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 )
 
 type EthClient struct {
-	rpc         *ethclient.Client
+	rpc         *rpcPool
 	ws          *ethclient.Client
-	wsURL       string
+	wsEndpoints []*endpoint
+	wsCurrent   *endpoint
+	quorum      int
 	contract    common.Address
 	abi         abi.ABI
 	log         *zap.Logger
-	guardianKey string
-	executorKey string
+	guardian    Signer
+	executor    Signer
 	chainID     *big.Int
+	logChunk    uint64
+	confirms    uint64
+	confirmTag  string
+	logPoll     time.Duration
+	wsFailLimit int
+	bumpPercent uint64
+	maxFee      *big.Int
+	maxTxFee    *big.Int
+	dryRun      bool
+	nonces      *nonceManager
+	synced      atomic.Uint64
 	mu          sync.Mutex
+
+	oracleABI     abi.ABI
+	oracleChecked bool
+	oraclePresent bool
+	tokens        map[common.Address]TokenInfo
+
+	gasMultiplier   float64
+	approveGasMax   uint64
+	executeGasPerID uint64
+	maxBatch        int
 }
 
 func New(cfg config.Config, log *zap.Logger) (*EthClient, error) {
@@ -39,39 +63,134 @@ func New(cfg config.Config, log *zap.Logger) (*EthClient, error) {
 	if !common.IsHexAddress(cfg.ContractAddress) {
 		return nil, fmt.Errorf("invalid contract address")
 	}
-
-	rpc, err := ethclient.Dial(cfg.RPCUrl)
+	if _, err := blockTag(cfg.ConfirmationTag); err != nil {
+		return nil, err
+	}
+	if cfg.FeeBumpPercent < minBumpPercent {
+		return nil, fmt.Errorf("fee bump percent %d is below the %d%% nodes require for replacements", cfg.FeeBumpPercent, minBumpPercent)
+	}
+	if cfg.GasMultiplier < 1 {
+		return nil, fmt.Errorf("gas multiplier %.2f must be at least 1", cfg.GasMultiplier)
+	}
+	maxFee, ok := new(big.Int).SetString(cfg.MaxFeePerGas, 10)
+	if !ok || maxFee.Sign() < 0 {
+		return nil, fmt.Errorf("invalid max fee per gas %q", cfg.MaxFeePerGas)
+	}
+	maxTxFee, ok := new(big.Int).SetString(cfg.MaxTxFee, 10)
+	if !ok || maxTxFee.Sign() < 0 {
+		return nil, fmt.Errorf("invalid max tx fee %q", cfg.MaxTxFee)
+	}
+	rpcURLs := endpointURLs(cfg.RPCUrl, cfg.RPCUrls)
+	if cfg.RPCQuorum > len(rpcURLs) {
+		return nil, fmt.Errorf("rpc quorum %d exceeds %d configured endpoints", cfg.RPCQuorum, len(rpcURLs))
+	}
+	parsed, err := ParseTreasuryGuardABI()
+	if err != nil {
+		return nil, err
+	}
+	oracleABI, err := parseGasPriceOracleABI()
 	if err != nil {
 		return nil, err
 	}
 
-	parsed, err := ParseTreasuryGuardABI()
+	var guardian, executor Signer
+	// Observers never sign, so keys are not loaded even if configured.
+	if cfg.Mode != config.ModeObserver {
+		guardian, err = loadSigner("guardian", signerConfig{
+			key:          cfg.GuardianKey,
+			keystore:     cfg.GuardianKeystore,
+			passwordFile: cfg.GuardianPasswordFile,
+			url:          cfg.GuardianSignerURL,
+			address:      cfg.GuardianAddress,
+			method:       cfg.SignerMethod,
+			dryRun:       cfg.Mode == config.ModeDryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+		executor, err = loadSigner("executor", signerConfig{
+			key:          cfg.ExecutorKey,
+			keystore:     cfg.ExecutorKeystore,
+			passwordFile: cfg.ExecutorPasswordFile,
+			url:          cfg.ExecutorSignerURL,
+			address:      cfg.ExecutorAddress,
+			method:       cfg.SignerMethod,
+			dryRun:       cfg.Mode == config.ModeDryRun,
+		})
+		if err != nil {
+			closeSigner(guardian)
+			return nil, err
+		}
+	}
+
+	pool, err := dialPool(rpcURLs, cfg.BreakerThreshold, cfg.BreakerCooldown, log)
 	if err != nil {
-		rpc.Close()
+		closeSigner(guardian)
+		closeSigner(executor)
 		return nil, err
 	}
 
 	chainID := new(big.Int).SetUint64(cfg.ChainID)
 
 	client := &EthClient{
-		rpc:         rpc,
-		wsURL:       cfg.WSUrl,
+		rpc:         pool,
+		quorum:      cfg.RPCQuorum,
 		contract:    common.HexToAddress(cfg.ContractAddress),
 		abi:         parsed,
 		log:         log,
-		guardianKey: cfg.GuardianKey,
-		executorKey: cfg.ExecutorKey,
+		guardian:    guardian,
+		executor:    executor,
 		chainID:     chainID,
-	}
-
-	if err := client.dialWS(); err != nil {
-		rpc.Close()
-		return nil, err
+		logChunk:    cfg.LogChunkSize,
+		confirms:    cfg.Confirmations,
+		confirmTag:  cfg.ConfirmationTag,
+		logPoll:     cfg.LogPollInterval,
+		wsFailLimit: cfg.WSMaxFailures,
+		bumpPercent: cfg.FeeBumpPercent,
+		maxFee:      maxFee,
+		maxTxFee:    maxTxFee,
+		dryRun:      cfg.Mode == config.ModeDryRun,
+		nonces:      newNonceManager(pool, log),
+
+		gasMultiplier:   cfg.GasMultiplier,
+		approveGasMax:   cfg.ApproveGasMax,
+		executeGasPerID: cfg.ExecuteGasPerRequest,
+		maxBatch:        cfg.MaxBatch,
+
+		oracleABI: oracleABI,
+	}
+	for _, raw := range endpointURLs(cfg.WSUrl, cfg.WSUrls) {
+		client.wsEndpoints = append(client.wsEndpoints, &endpoint{name: endpointName(raw), url: raw})
+	}
+
+	if len(client.wsEndpoints) > 0 {
+		if err := client.dialWS(); err != nil {
+			log.Warn("websocket dial failed, will retry before falling back to polling", zap.Error(err))
+		}
 	}
 
 	return client, nil
 }
 
+// endpointURLs puts the primary URL first and appends extra endpoints,
+// skipping blanks and duplicates.
+func endpointURLs(primary string, extra []string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, raw := range append([]string{primary}, extra...) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if _, ok := seen[raw]; ok {
+			continue
+		}
+		seen[raw] = struct{}{}
+		out = append(out, raw)
+	}
+	return out
+}
+
 func (c *EthClient) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -84,6 +203,16 @@ func (c *EthClient) Close() {
 		c.rpc.Close()
 		c.rpc = nil
 	}
+	closeSigner(c.guardian)
+	closeSigner(c.executor)
+}
+
+func (c *EthClient) EndpointHealth() []EndpointHealth {
+	health := c.rpc.Health()
+	for _, ep := range c.wsEndpoints {
+		health = append(health, ep.health())
+	}
+	return health
 }
 
 func (c *EthClient) CheckChainID(ctx context.Context, expected uint64) (uint64, error) {
@@ -98,6 +227,13 @@ func (c *EthClient) CheckChainID(ctx context.Context, expected uint64) (uint64,
 }
 
 func (c *EthClient) ChainTime(ctx context.Context) (uint64, error) {
+	if c.quorum > 1 {
+		header, err := c.rpc.quorumHeader(ctx, c.quorum)
+		if err != nil {
+			return 0, err
+		}
+		return header.Time, nil
+	}
 	header, err := c.rpc.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return 0, err
@@ -105,24 +241,205 @@ func (c *EthClient) ChainTime(ctx context.Context) (uint64, error) {
 	return header.Time, nil
 }
 
-func (c *EthClient) Approve(ctx context.Context, id uint64) (common.Hash, error) {
-	data, err := c.abi.Pack("approve", new(big.Int).SetUint64(id))
+func (c *EthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.rpc.BlockNumber(ctx)
+}
+
+// ConfirmedBlock returns the newest block the watcher may treat as settled:
+// the configured tag (latest, safe or finalized) minus the confirmation depth.
+func (c *EthClient) ConfirmedBlock(ctx context.Context) (uint64, error) {
+	tag, err := blockTag(c.confirmTag)
+	if err != nil {
+		return 0, err
+	}
+	header, err := c.rpc.HeaderByNumber(ctx, tag)
+	if err != nil {
+		return 0, err
+	}
+	number := header.Number.Uint64()
+	if number < c.confirms {
+		return 0, nil
+	}
+	return number - c.confirms, nil
+}
+
+func (c *EthClient) CanonicalHash(ctx context.Context, number uint64) (common.Hash, error) {
+	header, err := c.rpc.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
 	if err != nil {
 		return common.Hash{}, err
 	}
-	return c.sendTx(ctx, c.guardianKey, data, 120000)
+	return header.Hash(), nil
+}
+
+func blockTag(name string) (*big.Int, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "latest":
+		return nil, nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	default:
+		return nil, fmt.Errorf("unknown block tag %q", name)
+	}
+}
+
+// SyncedBlock reports the highest block whose contract logs have all
+// been handed to the subscriber.
+func (c *EthClient) SyncedBlock() uint64 {
+	return c.synced.Load()
+}
+
+func (c *EthClient) markSynced(block uint64) {
+	for {
+		cur := c.synced.Load()
+		if block <= cur || c.synced.CompareAndSwap(cur, block) {
+			return
+		}
+	}
+}
+
+// DeploymentBlock binary searches for the first block at which the contract
+// has code. It needs an RPC endpoint that serves historical state.
+func (c *EthClient) DeploymentBlock(ctx context.Context) (uint64, error) {
+	head, err := c.rpc.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	code, err := c.rpc.CodeAt(ctx, c.contract, new(big.Int).SetUint64(head))
+	if err != nil {
+		return 0, err
+	}
+	if len(code) == 0 {
+		return 0, fmt.Errorf("no code at %s", c.contract.Hex())
+	}
+	lo, hi := uint64(0), head
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		code, err := c.rpc.CodeAt(ctx, c.contract, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if len(code) > 0 {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+func (c *EthClient) GuardianAddress() (common.Address, error) {
+	if c.guardian == nil {
+		return common.Address{}, fmt.Errorf("guardian: %w", ErrNoSigner)
+	}
+	return c.guardian.Address(), nil
+}
+
+// SignerAddresses returns the accounts the guardian and executor sign with.
+func (c *EthClient) SignerAddresses() []common.Address {
+	var out []common.Address
+	for _, s := range []Signer{c.guardian, c.executor} {
+		if s != nil {
+			out = append(out, s.Address())
+		}
+	}
+	return out
+}
+
+// HasCode reports whether account has contract code at the latest block.
+func (c *EthClient) HasCode(ctx context.Context, account common.Address) (bool, error) {
+	code, err := c.rpc.CodeAt(ctx, account, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(code) > 0, nil
+}
+
+func (c *EthClient) HasApproved(ctx context.Context, id uint64) (bool, error) {
+	guardian, err := c.GuardianAddress()
+	if err != nil {
+		return false, err
+	}
+	data, err := c.abi.Pack("approvalsByGuardian", new(big.Int).SetUint64(id), guardian)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.rpc.CallContract(ctx, ethereum.CallMsg{To: &c.contract, Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+	decoded, err := c.abi.Unpack("approvalsByGuardian", res)
+	if err != nil {
+		return false, err
+	}
+	approved, ok := decoded[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid approvalsByGuardian type")
+	}
+	return approved, nil
 }
 
-func (c *EthClient) ExecuteBatch(ctx context.Context, ids []uint64, gasFloor uint64) (common.Hash, error) {
+func (c *EthClient) Approve(ctx context.Context, id uint64) (SendResult, error) {
+	data, err := c.abi.Pack("approve", new(big.Int).SetUint64(id))
+	if err != nil {
+		return SendResult{}, err
+	}
+	return c.send(ctx, "guardian", c.guardian, data, 0, c.approveGasMax, false)
+}
+
+func (c *EthClient) ExecuteBatch(ctx context.Context, ids []uint64, gasFloor uint64) (SendResult, error) {
+	if c.maxBatch > 0 && len(ids) > c.maxBatch {
+		return SendResult{}, fmt.Errorf("batch of %d exceeds max batch %d", len(ids), c.maxBatch)
+	}
 	packedIDs := make([]*big.Int, 0, len(ids))
 	for _, id := range ids {
 		packedIDs = append(packedIDs, new(big.Int).SetUint64(id))
 	}
 	data, err := c.abi.Pack("executeBatch", packedIDs, new(big.Int).SetUint64(gasFloor))
 	if err != nil {
-		return common.Hash{}, err
+		return SendResult{}, err
 	}
-	return c.sendTx(ctx, c.executorKey, data, 800000)
+	bound := executeGasBound(len(ids), c.executeGasPerID, gasFloor)
+	return c.send(ctx, "executor", c.executor, data, gasFloor, bound, true)
+}
+
+// send simulates the call against the pending block, sizes its gas and
+// signs and broadcasts it. A call that would revert is skipped.
+func (c *EthClient) send(ctx context.Context, role string, s Signer, data []byte, reserve, bound uint64, clamp bool) (SendResult, error) {
+	if s == nil {
+		return SendResult{}, fmt.Errorf("%s: %w", role, ErrNoSigner)
+	}
+	revert, err := c.simulate(ctx, s.Address(), data)
+	if err != nil {
+		return SendResult{}, err
+	}
+	if revert != nil {
+		return skipped(revert), nil
+	}
+	limit, err := c.gasLimit(ctx, s.Address(), data, reserve, bound, clamp)
+	if err != nil {
+		return SendResult{}, err
+	}
+	fees, err := c.suggestFees(ctx)
+	if err != nil {
+		return SendResult{}, err
+	}
+	// The nonce barely changes the encoded size, so the fee is checked
+	// before one is reserved.
+	fee, err := c.checkFee(ctx, c.newTx(0, limit, data, fees))
+	if err != nil {
+		return SendResult{}, err
+	}
+	if c.dryRun {
+		c.log.Info("dry run, not sending", zap.String("from", s.Address().Hex()), zap.Uint64("gas", limit), zap.Stringer("max_fee", fee))
+		return SendResult{DryRun: true, Gas: limit, Fee: fee}, nil
+	}
+	hash, err := c.sendTx(ctx, s, data, limit, fees)
+	if err != nil {
+		return SendResult{}, classify(err)
+	}
+	return SendResult{Hash: hash, Gas: limit, Fee: fee}, nil
 }
 
 func (c *EthClient) GetRequest(ctx context.Context, id uint64) (RequestState, error) {
@@ -132,7 +449,12 @@ func (c *EthClient) GetRequest(ctx context.Context, id uint64) (RequestState, er
 	}
 
 	msg := ethereum.CallMsg{To: &c.contract, Data: data}
-	res, err := c.rpc.CallContract(ctx, msg, nil)
+	var res []byte
+	if c.quorum > 1 {
+		res, err = c.rpc.quorumCall(ctx, c.quorum, msg)
+	} else {
+		res, err = c.rpc.CallContract(ctx, msg, nil)
+	}
 	if err != nil {
 		return RequestState{}, err
 	}
@@ -148,149 +470,72 @@ func (c *EthClient) GetRequest(ctx context.Context, id uint64) (RequestState, er
 	return unpackRequest(decoded)
 }
 
-func (c *EthClient) sendTx(ctx context.Context, keyHex string, data []byte, gasLimit uint64) (common.Hash, error) {
-	keyHex = strings.TrimPrefix(keyHex, "0x")
-	priv, err := crypto.HexToECDSA(keyHex)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	from := crypto.PubkeyToAddress(priv.PublicKey)
-
-	nonce, err := c.rpc.PendingNonceAt(ctx, from)
-	if err != nil {
-		return common.Hash{}, err
-	}
-
-	tryDynamic := true
-	var tipCap *big.Int
-	if tip, err := c.rpc.SuggestGasTipCap(ctx); err == nil {
-		tipCap = tip
-	} else {
-		tryDynamic = false
-	}
-
-	if tryDynamic {
-		feeCap := new(big.Int)
-		if price, err := c.rpc.SuggestGasPrice(ctx); err == nil {
-			feeCap.Set(price)
-		} else {
-			feeCap.Set(tipCap)
-			feeCap.Mul(feeCap, big.NewInt(2))
+func (c *EthClient) sendTx(ctx context.Context, s Signer, data []byte, gasLimit uint64, fees txFees) (common.Hash, error) {
+	from := s.Address()
+	for attempt := 0; ; attempt++ {
+		nonce, err := c.nonces.acquire(ctx, from)
+		if err != nil {
+			return common.Hash{}, err
 		}
-		if feeCap.Cmp(tipCap) < 0 {
-			feeCap.Set(tipCap)
-			feeCap.Mul(feeCap, big.NewInt(2))
-		}
-
-		tx := types.NewTx(&types.DynamicFeeTx{
-			ChainID:   c.chainID,
-			Nonce:     nonce,
-			To:        &c.contract,
-			Gas:       gasLimit,
-			GasTipCap: tipCap,
-			GasFeeCap: feeCap,
-			Value:     big.NewInt(0),
-			Data:      data,
-		})
-
-		signed, err := types.SignTx(tx, types.LatestSignerForChainID(c.chainID), priv)
+		signed, err := s.SignTx(ctx, c.newTx(nonce, gasLimit, data, fees), c.chainID)
 		if err == nil {
 			err = c.rpc.SendTransaction(ctx, signed)
 		}
-		if err == nil {
+		if err == nil || isAlreadyKnown(err) {
+			// An endpoint that already has the transaction got it from an
+			// earlier broadcast, typically through another pool member.
+			c.nonces.sent(from, nonce, signed.Hash())
 			return signed.Hash(), nil
 		}
-		if isNonceTooLow(err) {
-			return c.retryWithNonce(ctx, priv, gasLimit, data, true)
+		if attempt == 0 && isNonceConflict(err) {
+			c.log.Warn("nonce rejected, resyncing", zap.String("signer", from.Hex()), zap.Uint64("nonce", nonce), zap.Error(err))
+			if err := c.nonces.resync(ctx, from); err != nil {
+				return common.Hash{}, err
+			}
+			continue
 		}
+		c.nonces.release(from, nonce)
 		return common.Hash{}, err
 	}
-
-	return c.sendLegacy(ctx, priv, nonce, gasLimit, data)
 }
 
-func (c *EthClient) retryWithNonce(ctx context.Context, priv *ecdsa.PrivateKey, gasLimit uint64, data []byte, dynamic bool) (common.Hash, error) {
-	from := crypto.PubkeyToAddress(priv.PublicKey)
-	nonce, err := c.rpc.PendingNonceAt(ctx, from)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	if dynamic {
-		return c.sendDynamic(ctx, priv, nonce, gasLimit, data)
-	}
-	return c.sendLegacy(ctx, priv, nonce, gasLimit, data)
-}
-
-func (c *EthClient) sendDynamic(ctx context.Context, priv *ecdsa.PrivateKey, nonce uint64, gasLimit uint64, data []byte) (common.Hash, error) {
-	tipCap, err := c.rpc.SuggestGasTipCap(ctx)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	feeCap := new(big.Int)
-	if price, err := c.rpc.SuggestGasPrice(ctx); err == nil {
-		feeCap.Set(price)
-	} else {
-		feeCap.Set(tipCap)
-		feeCap.Mul(feeCap, big.NewInt(2))
-	}
-	if feeCap.Cmp(tipCap) < 0 {
-		feeCap.Set(tipCap)
-		feeCap.Mul(feeCap, big.NewInt(2))
-	}
-
-	tx := types.NewTx(&types.DynamicFeeTx{
-		ChainID:   c.chainID,
-		Nonce:     nonce,
-		To:        &c.contract,
-		Gas:       gasLimit,
-		GasTipCap: tipCap,
-		GasFeeCap: feeCap,
-		Value:     big.NewInt(0),
-		Data:      data,
-	})
-
-	signed, err := types.SignTx(tx, types.LatestSignerForChainID(c.chainID), priv)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	if err := c.rpc.SendTransaction(ctx, signed); err != nil {
-		return common.Hash{}, err
-	}
-	return signed.Hash(), nil
+// UseNonceStore makes the nonce manager persist to store and reload each
+// signer from it on the next send.
+func (c *EthClient) UseNonceStore(store NonceStore) {
+	c.nonces.setStore(store)
 }
 
-func (c *EthClient) sendLegacy(ctx context.Context, priv *ecdsa.PrivateKey, nonce uint64, gasLimit uint64, data []byte) (common.Hash, error) {
-	price, err := c.rpc.SuggestGasPrice(ctx)
-	if err != nil {
-		return common.Hash{}, err
-	}
-
-	tx := types.NewTx(&types.LegacyTx{
-		Nonce:    nonce,
-		To:       &c.contract,
-		Gas:      gasLimit,
-		GasPrice: price,
-		Value:    big.NewInt(0),
-		Data:     data,
-	})
-
-	signed, err := types.SignTx(tx, types.LatestSignerForChainID(c.chainID), priv)
-	if err != nil {
-		return common.Hash{}, err
-	}
-	if err := c.rpc.SendTransaction(ctx, signed); err != nil {
-		if isNonceTooLow(err) {
-			return c.retryWithNonce(ctx, priv, gasLimit, data, false)
+// CheckNonces looks for nonce gaps on the configured signers and queues any
+// dropped nonces for reuse.
+func (c *EthClient) CheckNonces(ctx context.Context) (map[common.Address][]uint64, error) {
+	gaps := make(map[common.Address][]uint64)
+	for _, s := range []Signer{c.guardian, c.executor} {
+		if s == nil {
+			continue
 		}
-		return common.Hash{}, err
+		addr := s.Address()
+		if _, done := gaps[addr]; done {
+			continue
+		}
+		found, err := c.nonces.checkGaps(ctx, addr)
+		if err != nil {
+			return gaps, err
+		}
+		gaps[addr] = found
 	}
-	return signed.Hash(), nil
+	return gaps, nil
 }
 
 func isNonceTooLow(err error) bool {
-	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+	return errors.Is(classify(err), ErrNonceTooLow)
 }
 
+func isAlreadyKnown(err error) bool {
+	return errors.Is(classify(err), ErrAlreadyKnown)
+}
+
+// dialWS connects to the healthiest WebSocket endpoint, failing over to the
+// others when a dial fails.
 func (c *EthClient) dialWS() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -298,10 +543,39 @@ func (c *EthClient) dialWS() error {
 		c.ws.Close()
 		c.ws = nil
 	}
-	ws, err := ethclient.Dial(c.wsURL)
-	if err != nil {
-		return err
+	var lastErr error
+	for _, ep := range orderEndpoints(c.wsEndpoints) {
+		start := time.Now()
+		ws, err := ethclient.Dial(ep.url)
+		c.recordWS(ep, time.Since(start), err != nil)
+		if err != nil {
+			c.log.Warn("websocket dial failed", zap.String("endpoint", ep.name), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		c.ws = ws
+		c.wsCurrent = ep
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = ErrNoEndpoints
+	}
+	return lastErr
+}
+
+// reportWSFailure counts a dropped or refused subscription against the
+// endpoint it was opened on.
+func (c *EthClient) reportWSFailure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wsCurrent != nil {
+		c.recordWS(c.wsCurrent, 0, true)
+	}
+}
+
+func (c *EthClient) recordWS(ep *endpoint, took time.Duration, failed bool) {
+	state, changed := ep.record(took, failed, c.rpc.threshold, c.rpc.cooldown)
+	if changed {
+		c.log.Warn("websocket endpoint breaker changed", zap.String("endpoint", ep.name), zap.String("state", state.String()))
 	}
-	c.ws = ws
-	return nil
 }
@@ -0,0 +1,431 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// reorgWindow bounds how many recent block hashes the polling source keeps to
+// find the fork point after a reorg.
+const reorgWindow = 128
+
+// logCursor remembers the position of the last delivered log so that
+// backfilled ranges and the live subscription never emit the same log twice.
+type logCursor struct {
+	block uint64
+	index uint
+	set   bool
+}
+
+func (c logCursor) seen(lg types.Log) bool {
+	if !c.set {
+		return false
+	}
+	if lg.BlockNumber != c.block {
+		return lg.BlockNumber < c.block
+	}
+	return lg.Index <= c.index
+}
+
+func (c *logCursor) advance(lg types.Log) {
+	c.block = lg.BlockNumber
+	c.index = lg.Index
+	c.set = true
+}
+
+// rewind moves the cursor to just before lg so a re-included copy of a
+// reorged log is delivered again.
+func (c *logCursor) rewind(lg types.Log) {
+	if !c.seen(lg) {
+		return
+	}
+	switch {
+	case lg.Index > 0:
+		c.block, c.index = lg.BlockNumber, lg.Index-1
+	case lg.BlockNumber > 0:
+		c.block, c.index = lg.BlockNumber-1, ^uint(0)
+	default:
+		*c = logCursor{}
+	}
+}
+
+// eventStream holds the delivery state shared by the WebSocket and polling
+// log sources, so switching between them keeps the same cursor.
+type eventStream struct {
+	c      *EthClient
+	query  ethereum.FilterQuery
+	out    chan Event
+	errCh  chan error
+	cursor logCursor
+	next   uint64
+
+	backfilled bool
+
+	// polling only: recent block hashes and the logs delivered from them
+	hashes map[uint64]common.Hash
+	logs   map[uint64][]types.Log
+	tip    uint64
+}
+
+// SubscribeEvents streams every TreasuryGuard event starting at fromBlock.
+// Historical logs up to the current head are fetched with chunked eth_getLogs
+// while the live subscription is already open, and the same catch-up runs
+// after every reconnect so no block range is skipped. Logs dropped by a reorg
+// are delivered with Removed set so subscribers can roll back.
+//
+// Without a WS_URL, or after WS_MAX_FAILURES consecutive WebSocket failures,
+// the stream switches to polling eth_getLogs over HTTP.
+func (c *EthClient) SubscribeEvents(ctx context.Context, fromBlock uint64) (<-chan Event, <-chan error) {
+	s := &eventStream{
+		c:     c,
+		out:   make(chan Event),
+		errCh: make(chan error, 1),
+		next:  fromBlock,
+		query: ethereum.FilterQuery{
+			Addresses: []common.Address{c.contract},
+			Topics:    [][]common.Hash{c.eventTopics()},
+		},
+		hashes: make(map[uint64]common.Hash),
+		logs:   make(map[uint64][]types.Log),
+	}
+
+	go func() {
+		defer close(s.out)
+		defer close(s.errCh)
+
+		if len(c.wsEndpoints) == 0 {
+			c.log.Info("no websocket endpoint, polling logs over http", zap.Duration("interval", c.logPoll))
+			s.poll(ctx)
+			return
+		}
+
+		failures := 0
+		for ctx.Err() == nil {
+			connected, err := s.subscribe(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				sendErr(s.errCh, err)
+			}
+			if connected {
+				failures = 0
+			}
+			failures++
+			if c.wsFailLimit > 0 && failures >= c.wsFailLimit {
+				c.log.Warn("websocket keeps failing, falling back to http polling", zap.Int("failures", failures))
+				s.poll(ctx)
+				return
+			}
+			if !sleepCtx(ctx, 5*time.Second) {
+				return
+			}
+		}
+	}()
+
+	return s.out, s.errCh
+}
+
+// emit decodes and delivers lg unless the cursor says it was already sent.
+// It returns false only when ctx is cancelled.
+func (s *eventStream) emit(ctx context.Context, lg types.Log) bool {
+	if lg.Removed != s.cursor.seen(lg) {
+		return true
+	}
+	evt, err := s.c.DecodeEvent(lg)
+	if err != nil {
+		sendErr(s.errCh, err)
+		if !lg.Removed {
+			s.cursor.advance(lg)
+		}
+		return true
+	}
+	select {
+	case s.out <- evt:
+	case <-ctx.Done():
+		return false
+	}
+	if lg.Removed {
+		s.cursor.rewind(lg)
+		if lg.BlockNumber < s.next {
+			s.next = lg.BlockNumber
+		}
+	} else {
+		s.cursor.advance(lg)
+	}
+	return true
+}
+
+// catchUp delivers logs in [s.next, head] and moves s.next past head.
+func (s *eventStream) catchUp(ctx context.Context, head uint64, record bool) error {
+	if s.next <= head {
+		from := s.next
+		stopped := false
+		err := s.c.scanLogs(ctx, s.query, from, head, func(lg types.Log) bool {
+			if record && !s.cursor.seen(lg) {
+				s.logs[lg.BlockNumber] = append(s.logs[lg.BlockNumber], lg)
+				s.hashes[lg.BlockNumber] = lg.BlockHash
+			}
+			if !s.emit(ctx, lg) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+		if !record || !s.backfilled {
+			s.c.log.Info("backfill complete", zap.Uint64("from", from), zap.Uint64("to", head))
+		}
+		s.backfilled = true
+	}
+	if head+1 > s.next {
+		s.next = head + 1
+	}
+	s.c.markSynced(head)
+	return nil
+}
+
+// subscribe runs one WebSocket session. connected reports whether the
+// subscription was established before it failed.
+func (s *eventStream) subscribe(ctx context.Context) (connected bool, err error) {
+	if err := s.c.dialWS(); err != nil {
+		return false, err
+	}
+	logs := make(chan types.Log)
+	sub, err := s.c.ws.SubscribeFilterLogs(ctx, s.query, logs)
+	if err != nil {
+		s.c.reportWSFailure()
+		return false, err
+	}
+	defer sub.Unsubscribe()
+
+	head, err := s.c.rpc.BlockNumber(ctx)
+	if err != nil {
+		return false, err
+	}
+	if err := s.catchUp(ctx, head, false); err != nil {
+		return false, err
+	}
+
+	// A quiet contract sends no logs, so the synced block also follows the
+	// chain head, one interval behind to leave time for that head's logs to
+	// arrive.
+	interval := s.c.logPoll
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	heads := time.NewTicker(interval)
+	defer heads.Stop()
+	seen := head
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case <-heads.C:
+			s.c.markSynced(seen)
+			if latest, err := s.c.rpc.BlockNumber(ctx); err == nil {
+				seen = latest
+			}
+		case err := <-sub.Err():
+			if s.cursor.set && s.cursor.block >= s.next {
+				s.next = s.cursor.block
+			}
+			if err == nil {
+				err = errors.New("log subscription closed")
+			}
+			s.c.reportWSFailure()
+			return true, err
+		case lg := <-logs:
+			if !s.emit(ctx, lg) {
+				return true, nil
+			}
+			if !lg.Removed && lg.BlockNumber > 0 {
+				s.c.markSynced(lg.BlockNumber - 1)
+			}
+		}
+	}
+}
+
+// poll delivers logs by repeatedly calling eth_getLogs from a block cursor.
+// Reorgs are detected by re-checking the hash of the last polled head.
+func (s *eventStream) poll(ctx context.Context) {
+	for {
+		if err := s.pollOnce(ctx); err != nil && ctx.Err() == nil {
+			sendErr(s.errCh, err)
+		}
+		if !sleepCtx(ctx, s.c.logPoll) {
+			return
+		}
+	}
+}
+
+func (s *eventStream) pollOnce(ctx context.Context) error {
+	if err := s.detectReorg(ctx); err != nil {
+		return err
+	}
+	header, err := s.c.rpc.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	head := header.Number.Uint64()
+	if err := s.catchUp(ctx, head, true); err != nil {
+		return err
+	}
+	s.tip = head
+	s.hashes[head] = header.Hash()
+	s.prune(head)
+	return nil
+}
+
+// detectReorg compares the recorded hash of the last polled head with the
+// canonical chain. On a mismatch it walks back through recorded blocks to
+// the newest one that is still canonical, emits Removed copies of every log
+// delivered above it and rewinds the cursor so the new branch is scanned.
+func (s *eventStream) detectReorg(ctx context.Context) error {
+	recorded, ok := s.hashes[s.tip]
+	if !ok {
+		return nil
+	}
+	canonical, err := s.c.CanonicalHash(ctx, s.tip)
+	if err != nil {
+		return err
+	}
+	if canonical == recorded {
+		return nil
+	}
+
+	blocks := make([]uint64, 0, len(s.hashes))
+	for n := range s.hashes {
+		blocks = append(blocks, n)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] > blocks[j] })
+
+	fork := uint64(0)
+	found := false
+	for _, n := range blocks {
+		hash, err := s.c.CanonicalHash(ctx, n)
+		if err != nil {
+			return err
+		}
+		if hash == s.hashes[n] {
+			fork, found = n, true
+			break
+		}
+	}
+	if !found && len(blocks) > 0 {
+		fork = blocks[len(blocks)-1] - 1
+		s.c.log.Error("reorg deeper than tracked window", zap.Uint64("tip", s.tip), zap.Uint64("assumed_fork", fork))
+	}
+	s.c.log.Warn("reorg detected while polling", zap.Uint64("tip", s.tip), zap.Uint64("fork_block", fork))
+
+	for _, n := range blocks {
+		if n <= fork {
+			break
+		}
+		logs := s.logs[n]
+		for i := len(logs) - 1; i >= 0; i-- {
+			removed := logs[i]
+			removed.Removed = true
+			if !s.emit(ctx, removed) {
+				return ctx.Err()
+			}
+		}
+		delete(s.logs, n)
+		delete(s.hashes, n)
+	}
+	if s.cursor.set && s.cursor.block > fork {
+		s.cursor = logCursor{block: fork, index: ^uint(0), set: true}
+	}
+	if s.next > fork+1 {
+		s.next = fork + 1
+	}
+	s.tip = fork
+	return nil
+}
+
+func (s *eventStream) prune(head uint64) {
+	if head < reorgWindow {
+		return
+	}
+	floor := head - reorgWindow
+	for n := range s.hashes {
+		if n < floor {
+			delete(s.hashes, n)
+			delete(s.logs, n)
+		}
+	}
+}
+
+// scanLogs runs the query over [from, to] in ranges of at most logChunk
+// blocks, handing each log to fn in order until fn returns false.
+func (c *EthClient) scanLogs(ctx context.Context, query ethereum.FilterQuery, from, to uint64, fn func(types.Log) bool) error {
+	for _, r := range blockRanges(from, to, c.logChunk) {
+		q := query
+		q.FromBlock = new(big.Int).SetUint64(r[0])
+		q.ToBlock = new(big.Int).SetUint64(r[1])
+		logs, err := c.rpc.FilterLogs(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, lg := range logs {
+			if !fn(lg) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func blockRanges(from, to, size uint64) [][2]uint64 {
+	if from > to {
+		return nil
+	}
+	if size == 0 {
+		size = 1
+	}
+	ranges := make([][2]uint64, 0, (to-from)/size+1)
+	for start := from; ; {
+		end := start + size - 1
+		if end > to || end < start {
+			end = to
+		}
+		ranges = append(ranges, [2]uint64{start, end})
+		if end == to {
+			break
+		}
+		start = end + 1
+	}
+	return ranges
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func sendErr(ch chan error, err error) {
+	select {
+	case ch <- err:
+	default:
+	}
+}
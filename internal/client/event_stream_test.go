@@ -0,0 +1,230 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+func TestBlockRangesChunking(t *testing.T) {
+	got := blockRanges(100, 104, 2)
+	want := [][2]uint64{{100, 101}, {102, 103}, {104, 104}}
+	if len(got) != len(want) {
+		t.Fatalf("got %d ranges want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("range %d: got %v want %v", i, got[i], want[i])
+		}
+	}
+
+	if r := blockRanges(5, 4, 10); len(r) != 0 {
+		t.Fatalf("expected no ranges when from > to")
+	}
+	if r := blockRanges(7, 7, 0); len(r) != 1 || r[0] != [2]uint64{7, 7} {
+		t.Fatalf("expected single block range, got %v", r)
+	}
+}
+
+func TestLogCursorDedup(t *testing.T) {
+	var cursor logCursor
+	first := types.Log{BlockNumber: 10, Index: 2}
+	if cursor.seen(first) {
+		t.Fatalf("empty cursor should not report seen")
+	}
+	cursor.advance(first)
+
+	cases := []struct {
+		name string
+		lg   types.Log
+		seen bool
+	}{
+		{"sameLog", types.Log{BlockNumber: 10, Index: 2}, true},
+		{"earlierIndex", types.Log{BlockNumber: 10, Index: 1}, true},
+		{"earlierBlock", types.Log{BlockNumber: 9, Index: 7}, true},
+		{"laterIndex", types.Log{BlockNumber: 10, Index: 3}, false},
+		{"laterBlock", types.Log{BlockNumber: 11, Index: 0}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cursor.seen(tc.lg); got != tc.seen {
+				t.Fatalf("got %v want %v", got, tc.seen)
+			}
+		})
+	}
+}
+
+func TestLogCursorRewind(t *testing.T) {
+	var cursor logCursor
+	cursor.advance(types.Log{BlockNumber: 20, Index: 4})
+
+	cursor.rewind(types.Log{BlockNumber: 20, Index: 2})
+	if cursor.seen(types.Log{BlockNumber: 20, Index: 2}) {
+		t.Fatalf("expected removed log position to be deliverable again")
+	}
+	if !cursor.seen(types.Log{BlockNumber: 20, Index: 1}) {
+		t.Fatalf("expected earlier log to stay seen")
+	}
+
+	cursor.rewind(types.Log{BlockNumber: 18, Index: 0})
+	if cursor.seen(types.Log{BlockNumber: 18, Index: 0}) || !cursor.seen(types.Log{BlockNumber: 17, Index: 9}) {
+		t.Fatalf("expected cursor to move to end of previous block")
+	}
+
+	cursor.rewind(types.Log{BlockNumber: 30, Index: 0})
+	if cursor.seen(types.Log{BlockNumber: 18, Index: 0}) {
+		t.Fatalf("rewind past the cursor should be a no-op")
+	}
+}
+
+// fakeChain serves the handful of eth_ methods the polling source needs.
+// Each block's hash depends on its fork tag so a reorg changes hashes.
+type fakeChain struct {
+	mu   sync.Mutex
+	head uint64
+	fork map[uint64]string
+	logs []types.Log
+}
+
+func (f *fakeChain) header(n uint64) *types.Header {
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(n),
+		Difficulty: big.NewInt(0),
+		Extra:      []byte(f.fork[n]),
+	}
+}
+
+func (f *fakeChain) BlockNumber() hexUint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return hexUint(f.head)
+}
+
+func (f *fakeChain) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := f.head
+	if number != "latest" {
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(number, "0x"), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		n = parsed
+	}
+	return f.header(n), nil
+}
+
+func (f *fakeChain) GetLogs(crit map[string]interface{}) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	from, err := strconv.ParseUint(strings.TrimPrefix(crit["fromBlock"].(string), "0x"), 16, 64)
+	if err != nil {
+		return nil, err
+	}
+	to, err := strconv.ParseUint(strings.TrimPrefix(crit["toBlock"].(string), "0x"), 16, 64)
+	if err != nil {
+		return nil, err
+	}
+	out := []types.Log{}
+	for _, lg := range f.logs {
+		if lg.BlockNumber >= from && lg.BlockNumber <= to {
+			lg.BlockHash = f.header(lg.BlockNumber).Hash()
+			out = append(out, lg)
+		}
+	}
+	return out, nil
+}
+
+type hexUint uint64
+
+func (h hexUint) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("0x%x", uint64(h))), nil
+}
+
+func TestPollingSourceRollsBackReorgedLogs(t *testing.T) {
+	parsed, err := ParseTreasuryGuardABI()
+	if err != nil {
+		t.Fatalf("parse abi: %v", err)
+	}
+	created := parsed.Events["RequestCreated"]
+	data, err := created.Inputs.NonIndexed().Pack(big.NewInt(1), big.NewInt(1), common.Address{}, uint64(0))
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	createdLog := func(block uint64) types.Log {
+		return types.Log{
+			Topics:      []common.Hash{created.ID, common.BigToHash(big.NewInt(1)), {}, {}},
+			Data:        data,
+			BlockNumber: block,
+		}
+	}
+
+	chain := &fakeChain{head: 5, fork: map[uint64]string{}, logs: []types.Log{createdLog(3)}}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", chain); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer srv.Stop()
+
+	c := &EthClient{
+		rpc:      newPool(map[string]*ethclient.Client{"inproc": ethclient.NewClient(rpc.DialInProc(srv))}, zap.NewNop()),
+		abi:      parsed,
+		log:      zap.NewNop(),
+		logChunk: 2,
+	}
+	s := &eventStream{
+		c:      c,
+		out:    make(chan Event, 8),
+		errCh:  make(chan error, 1),
+		next:   1,
+		hashes: make(map[uint64]common.Hash),
+		logs:   make(map[uint64][]types.Log),
+	}
+	ctx := context.Background()
+
+	if err := s.pollOnce(ctx); err != nil {
+		t.Fatalf("first poll: %v", err)
+	}
+	first := (<-s.out).(RequestCreatedEvent)
+	if first.BlockNumber != 3 || first.Removed {
+		t.Fatalf("unexpected first event %+v", first.EventMeta)
+	}
+	if c.SyncedBlock() != 5 {
+		t.Fatalf("expected synced block 5, got %d", c.SyncedBlock())
+	}
+
+	chain.mu.Lock()
+	for n := uint64(3); n <= 6; n++ {
+		chain.fork[n] = "b"
+	}
+	chain.head = 6
+	chain.logs = []types.Log{createdLog(4)}
+	chain.mu.Unlock()
+
+	if err := s.pollOnce(ctx); err != nil {
+		t.Fatalf("second poll: %v", err)
+	}
+	removed := (<-s.out).(RequestCreatedEvent)
+	if !removed.Removed || removed.BlockNumber != 3 {
+		t.Fatalf("expected removed copy of block 3 log, got %+v", removed.EventMeta)
+	}
+	readded := (<-s.out).(RequestCreatedEvent)
+	if readded.Removed || readded.BlockNumber != 4 {
+		t.Fatalf("expected re-included log at block 4, got %+v", readded.EventMeta)
+	}
+	select {
+	case evt := <-s.out:
+		t.Fatalf("unexpected extra event %+v", evt)
+	default:
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// gasPriceOracle is the OP-stack predeploy that prices the L1 data fee
+// charged on top of L2 execution gas.
+var gasPriceOracle = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+const gasPriceOracleABIJSON = `[
+  {
+    "type": "function",
+    "name": "getL1Fee",
+    "stateMutability": "view",
+    "inputs": [{ "name": "_data", "type": "bytes" }],
+    "outputs": [{ "name": "", "type": "uint256" }]
+  }
+]`
+
+func parseGasPriceOracleABI() (abi.ABI, error) {
+	return abi.JSON(strings.NewReader(gasPriceOracleABIJSON))
+}
+
+var ErrFeeAboveCeiling = errors.New("transaction fee exceeds max tx fee")
+
+// FeeCeilingError reports a send that was not signed because its worst case
+// fee is over MAX_TX_FEE. It is expected to pass once fees drop.
+type FeeCeilingError struct {
+	Fee     *big.Int
+	L1Fee   *big.Int
+	Ceiling *big.Int
+}
+
+func (e *FeeCeilingError) Error() string {
+	return fmt.Sprintf("%v: fee %s wei (l1 %s), max %s", ErrFeeAboveCeiling, e.Fee, e.L1Fee, e.Ceiling)
+}
+
+func (e *FeeCeilingError) Unwrap() error {
+	return ErrFeeAboveCeiling
+}
+
+type txFees struct {
+	dynamic bool
+	tip     *big.Int
+	feeCap  *big.Int
+}
+
+// suggestFees picks EIP-1559 fees when the node supports them and a legacy
+// gas price otherwise, held to MAX_FEE_PER_GAS like fee bumps are.
+func (c *EthClient) suggestFees(ctx context.Context) (txFees, error) {
+	tip, err := c.rpc.SuggestGasTipCap(ctx)
+	if err != nil {
+		price, err := c.rpc.SuggestGasPrice(ctx)
+		if err != nil {
+			return txFees{}, err
+		}
+		return capFees(txFees{tip: price, feeCap: price}, c.maxFee), nil
+	}
+	feeCap := new(big.Int)
+	if price, err := c.rpc.SuggestGasPrice(ctx); err == nil {
+		feeCap.Set(price)
+	} else {
+		feeCap.Mul(tip, big.NewInt(2))
+	}
+	if feeCap.Cmp(tip) < 0 {
+		feeCap.Mul(tip, big.NewInt(2))
+	}
+	return capFees(txFees{dynamic: true, tip: tip, feeCap: feeCap}, c.maxFee), nil
+}
+
+// capFees lowers the fee cap, and the tip with it, to a non-zero maxFee.
+func capFees(fees txFees, maxFee *big.Int) txFees {
+	if maxFee == nil || maxFee.Sign() == 0 || fees.feeCap.Cmp(maxFee) <= 0 {
+		return fees
+	}
+	fees.feeCap = new(big.Int).Set(maxFee)
+	if fees.tip.Cmp(maxFee) > 0 {
+		fees.tip = new(big.Int).Set(maxFee)
+	}
+	return fees
+}
+
+func (c *EthClient) newTx(nonce, gasLimit uint64, data []byte, fees txFees) *types.Transaction {
+	if fees.dynamic {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   c.chainID,
+			Nonce:     nonce,
+			To:        &c.contract,
+			Gas:       gasLimit,
+			GasTipCap: fees.tip,
+			GasFeeCap: fees.feeCap,
+			Value:     big.NewInt(0),
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &c.contract,
+		Gas:      gasLimit,
+		GasPrice: fees.feeCap,
+		Value:    big.NewInt(0),
+		Data:     data,
+	})
+}
+
+// checkFee returns the most tx could cost, its full gas limit at the fee
+// cap plus the L1 data fee, and a FeeCeilingError when that is over the
+// configured max tx fee. With a zero ceiling the fee is only worked out in
+// dry-run mode, where it is reported; otherwise nil is returned.
+func (c *EthClient) checkFee(ctx context.Context, tx *types.Transaction) (*big.Int, error) {
+	if (c.maxTxFee == nil || c.maxTxFee.Sign() == 0) && !c.dryRun {
+		return nil, nil
+	}
+	l1Fee, err := c.l1Fee(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return feeWithin(tx, l1Fee, c.maxTxFee)
+}
+
+func feeWithin(tx *types.Transaction, l1Fee, ceiling *big.Int) (*big.Int, error) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	fee.Add(fee, l1Fee)
+	if ceiling != nil && ceiling.Sign() > 0 && fee.Cmp(ceiling) > 0 {
+		return fee, &FeeCeilingError{Fee: fee, L1Fee: l1Fee, Ceiling: ceiling}
+	}
+	return fee, nil
+}
+
+// l1Fee asks the GasPriceOracle what tx's calldata costs to post to L1.
+// Chains without the predeploy, such as a local Anvil, have no L1 fee.
+func (c *EthClient) l1Fee(ctx context.Context, tx *types.Transaction) (*big.Int, error) {
+	c.mu.Lock()
+	checked, present := c.oracleChecked, c.oraclePresent
+	c.mu.Unlock()
+	if !checked {
+		code, err := c.rpc.CodeAt(ctx, gasPriceOracle, nil)
+		if err != nil {
+			return nil, err
+		}
+		present = len(code) > 0
+		c.mu.Lock()
+		c.oracleChecked, c.oraclePresent = true, present
+		c.mu.Unlock()
+		if !present {
+			c.log.Info("no gas price oracle predeploy, ignoring l1 data fee")
+		}
+	}
+	if !present {
+		return new(big.Int), nil
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.oracleABI.Pack("getL1Fee", raw)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.rpc.CallContract(ctx, ethereum.CallMsg{To: &gasPriceOracle, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("l1 fee: %w", err)
+	}
+	out, err := c.oracleABI.Unpack("getL1Fee", res)
+	if err != nil {
+		return nil, err
+	}
+	fee, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected l1 fee type %T", out[0])
+	}
+	c.log.Debug("l1 data fee", zap.String("fee", fee.String()), zap.Int("bytes", len(raw)))
+	return fee, nil
+}
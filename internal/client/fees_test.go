@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFeeWithinCeiling(t *testing.T) {
+	c := &EthClient{chainID: big.NewInt(8453), contract: common.HexToAddress("0x01")}
+	gwei := big.NewInt(1_000_000_000)
+	// 100k gas at a 1 gwei fee cap costs at most 1e14 wei on L2.
+	txs := map[string]txFees{
+		"dynamic": {dynamic: true, tip: big.NewInt(1), feeCap: gwei},
+		"legacy":  {tip: gwei, feeCap: gwei},
+	}
+	cases := []struct {
+		name    string
+		l1Fee   int64
+		ceiling int64
+		over    bool
+	}{
+		{"disabled", 1e18, 0, false},
+		{"l2AtCeiling", 0, 1e14, false},
+		{"l1PushesOver", 1, 1e14, true},
+		{"l1Under", 5e13, 2e14, false},
+	}
+	for kind, fees := range txs {
+		tx := c.newTx(0, 100000, nil, fees)
+		for _, tc := range cases {
+			t.Run(kind+"/"+tc.name, func(t *testing.T) {
+				_, err := feeWithin(tx, big.NewInt(tc.l1Fee), big.NewInt(tc.ceiling))
+				if got := errors.Is(err, ErrFeeAboveCeiling); got != tc.over {
+					t.Fatalf("over ceiling %v, want %v (%v)", got, tc.over, err)
+				}
+				var ceilErr *FeeCeilingError
+				if tc.over && (!errors.As(err, &ceilErr) || ceilErr.Fee.Cmp(big.NewInt(1e14+tc.l1Fee)) != 0) {
+					t.Fatalf("unexpected ceiling error %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestCapFeesAtMaxFeePerGas(t *testing.T) {
+	gwei := big.NewInt(1_000_000_000)
+	suggested := txFees{dynamic: true, tip: big.NewInt(2_000_000_000), feeCap: big.NewInt(5_000_000_000)}
+
+	if got := capFees(suggested, nil); got.feeCap.Cmp(suggested.feeCap) != 0 {
+		t.Fatalf("expected no cap without MAX_FEE_PER_GAS, got %s", got.feeCap)
+	}
+	if got := capFees(suggested, big.NewInt(10_000_000_000)); got.feeCap.Cmp(suggested.feeCap) != 0 || got.tip.Cmp(suggested.tip) != 0 {
+		t.Fatalf("expected fees under the cap unchanged, got %+v", got)
+	}
+	got := capFees(suggested, gwei)
+	if got.feeCap.Cmp(gwei) != 0 || got.tip.Cmp(gwei) != 0 || !got.dynamic {
+		t.Fatalf("expected fee cap and tip held to 1 gwei, got %+v", got)
+	}
+	if suggested.feeCap.Cmp(big.NewInt(5_000_000_000)) != 0 {
+		t.Fatalf("capping must not modify the suggestion")
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// executeBaseGas covers executeBatch's fixed cost (calldata, the processed
+// array and the BatchExecuted log) on top of the per-request allowance.
+const executeBaseGas = 60000
+
+var (
+	ErrGasAboveBlockLimit = errors.New("gas estimate exceeds block gas limit")
+	ErrGasAboveBound      = errors.New("gas estimate exceeds configured bound")
+)
+
+// executeGasBound is the most gas an executeBatch of n requests may reserve.
+// It grows with the batch and keeps gasFloor on top so the contract's
+// gas-floor check does not end the batch early.
+func executeGasBound(n int, perRequest, gasFloor uint64) uint64 {
+	return executeBaseGas + uint64(n)*perRequest + gasFloor
+}
+
+// gasLimit estimates the call with eth_estimateGas, applies the safety
+// multiplier and adds reserve. A limit above bound is clamped to it when
+// clamp is set, which suits executeBatch since it stops at the gas floor
+// rather than reverting; otherwise it is an error. Estimates that cannot fit
+// in a block are always rejected.
+func (c *EthClient) gasLimit(ctx context.Context, from common.Address, data []byte, reserve, bound uint64, clamp bool) (uint64, error) {
+	estimate, err := c.rpc.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &c.contract, Data: data})
+	if err != nil {
+		return 0, fmt.Errorf("estimate gas: %w", classify(err))
+	}
+	header, err := c.rpc.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return planGas(estimate, c.gasMultiplier, reserve, bound, header.GasLimit, clamp, c.log)
+}
+
+func planGas(estimate uint64, multiplier float64, reserve, bound, blockLimit uint64, clamp bool, log *zap.Logger) (uint64, error) {
+	if blockLimit > 0 && estimate > blockLimit {
+		return 0, fmt.Errorf("%w: estimate %d, block limit %d", ErrGasAboveBlockLimit, estimate, blockLimit)
+	}
+	limit := uint64(float64(estimate)*multiplier) + reserve
+	if bound > 0 && limit > bound {
+		if !clamp && estimate+reserve > bound {
+			return 0, fmt.Errorf("%w: estimate %d, bound %d", ErrGasAboveBound, estimate, bound)
+		}
+		if estimate+reserve > bound {
+			log.Warn("gas estimate above batch bound, batch may stop early", zap.Uint64("estimate", estimate), zap.Uint64("bound", bound))
+		}
+		limit = bound
+	}
+	if blockLimit > 0 && limit > blockLimit {
+		limit = blockLimit
+	}
+	return limit, nil
+}
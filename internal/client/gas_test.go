@@ -0,0 +1,56 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestPlanGas(t *testing.T) {
+	cases := []struct {
+		name       string
+		estimate   uint64
+		reserve    uint64
+		bound      uint64
+		blockLimit uint64
+		clamp      bool
+		want       uint64
+		wantErr    error
+	}{
+		{"multiplierAndReserve", 100000, 50000, 1_000_000, 30_000_000, true, 175000, nil},
+		{"clampedToBound", 400000, 50000, 300000, 30_000_000, true, 300000, nil},
+		{"boundRejectsApprove", 250000, 0, 200000, 30_000_000, false, 0, ErrGasAboveBound},
+		{"multiplierTrimmedToBound", 180000, 0, 200000, 30_000_000, false, 200000, nil},
+		{"aboveBlockLimit", 31_000_000, 0, 0, 30_000_000, true, 0, ErrGasAboveBlockLimit},
+		{"cappedAtBlockLimit", 29_000_000, 0, 0, 30_000_000, true, 30_000_000, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := planGas(tc.estimate, 1.25, tc.reserve, tc.bound, tc.blockLimit, tc.clamp, zap.NewNop())
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %d want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteGasBoundScalesWithBatch(t *testing.T) {
+	small := executeGasBound(1, 150000, 50000)
+	large := executeGasBound(10, 150000, 50000)
+	if small != executeBaseGas+150000+50000 {
+		t.Fatalf("unexpected single request bound %d", small)
+	}
+	if large-small != 9*150000 {
+		t.Fatalf("expected bound to grow by the per-request allowance, got %d", large-small)
+	}
+}
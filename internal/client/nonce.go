@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+)
+
+// NonceState is one signer's nonce bookkeeping: the next fresh nonce, nonces
+// below it that are free again, and the hash last broadcast for each nonce
+// that has not been mined yet. A zero hash marks a nonce handed out whose
+// send has not finished.
+type NonceState struct {
+	Next     uint64                 `json:"next"`
+	Gaps     []uint64               `json:"gaps,omitempty"`
+	InFlight map[uint64]common.Hash `json:"inFlight,omitempty"`
+}
+
+// NonceStore persists nonce state so a restart neither reuses a nonce that
+// was already broadcast nor leaves one unused.
+type NonceStore interface {
+	Nonce(signer common.Address) (NonceState, bool)
+	PutNonce(signer common.Address, state NonceState)
+}
+
+type nonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+}
+
+type signerNonces struct {
+	NonceState
+	synced bool
+}
+
+// nonceManager hands out nonces per signer from local state instead of
+// asking the node before every send. Nonces whose send failed are reused
+// first, and the chain is consulted on startup, on nonce errors and during
+// periodic gap checks.
+type nonceManager struct {
+	mu      sync.Mutex
+	src     nonceSource
+	store   NonceStore
+	log     *zap.Logger
+	signers map[common.Address]*signerNonces
+}
+
+func newNonceManager(src nonceSource, log *zap.Logger) *nonceManager {
+	return &nonceManager{src: src, log: log, signers: make(map[common.Address]*signerNonces)}
+}
+
+func (m *nonceManager) setStore(store NonceStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store = store
+	for _, s := range m.signers {
+		s.synced = false
+	}
+}
+
+func (m *nonceManager) signer(addr common.Address) *signerNonces {
+	s, ok := m.signers[addr]
+	if !ok {
+		s = &signerNonces{NonceState: NonceState{InFlight: make(map[uint64]common.Hash)}}
+		m.signers[addr] = s
+	}
+	return s
+}
+
+// load starts from the persisted state, moved forward to the node's pending
+// nonce if another sender has used nonces since.
+func (m *nonceManager) load(ctx context.Context, addr common.Address, s *signerNonces) error {
+	pending, err := m.src.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return err
+	}
+	s.Next, s.Gaps, s.InFlight = pending, nil, make(map[uint64]common.Hash)
+	if m.store != nil {
+		if saved, ok := m.store.Nonce(addr); ok && saved.Next > pending {
+			s.Next = saved.Next
+			for _, n := range saved.Gaps {
+				if n >= pending && n < saved.Next {
+					s.Gaps = insertGap(s.Gaps, n)
+				}
+			}
+			// A zero hash means the process stopped mid-send; the gap check
+			// reuses the nonce if the node never saw it.
+			for n, hash := range saved.InFlight {
+				if n >= pending && hash != (common.Hash{}) {
+					s.InFlight[n] = hash
+				}
+			}
+		}
+	}
+	s.synced = true
+	m.persist(addr, s)
+	return nil
+}
+
+func (m *nonceManager) acquire(ctx context.Context, addr common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.signer(addr)
+	if !s.synced {
+		if err := m.load(ctx, addr, s); err != nil {
+			return 0, err
+		}
+	}
+	var nonce uint64
+	if len(s.Gaps) > 0 {
+		nonce, s.Gaps = s.Gaps[0], s.Gaps[1:]
+	} else {
+		nonce = s.Next
+		s.Next++
+	}
+	s.InFlight[nonce] = common.Hash{}
+	m.persist(addr, s)
+	return nonce, nil
+}
+
+// sent records the hash broadcast for nonce, replacing any earlier version.
+func (m *nonceManager) sent(addr common.Address, nonce uint64, hash common.Hash) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.signer(addr)
+	s.InFlight[nonce] = hash
+	m.persist(addr, s)
+}
+
+// release returns a nonce whose transaction never reached the network.
+func (m *nonceManager) release(addr common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.signer(addr)
+	delete(s.InFlight, nonce)
+	if nonce+1 == s.Next {
+		s.Next--
+	} else if nonce < s.Next {
+		s.Gaps = insertGap(s.Gaps, nonce)
+	}
+	m.persist(addr, s)
+}
+
+// resync drops local state after the node rejected a nonce and starts again
+// from its pending nonce.
+func (m *nonceManager) resync(ctx context.Context, addr common.Address) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.signer(addr)
+	pending, err := m.src.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return err
+	}
+	m.log.Warn("nonce resync", zap.String("signer", addr.Hex()), zap.Uint64("local_next", s.Next), zap.Uint64("pending", pending))
+	s.Next, s.Gaps, s.synced = pending, nil, true
+	for n := range s.InFlight {
+		if n >= pending {
+			delete(s.InFlight, n)
+		}
+	}
+	m.prune(s, pending)
+	m.persist(addr, s)
+	return nil
+}
+
+// checkGaps compares a signer's nonces with the node. Nonces below its
+// pending nonce are settled. If another sender moved the pending nonce past
+// ours it is adopted. Any nonce between the two that the node no longer
+// knows was dropped and is queued for reuse, since every later nonce is
+// stuck behind it. It returns the nonces newly marked as gaps.
+func (m *nonceManager) checkGaps(ctx context.Context, addr common.Address) ([]uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.signers[addr]
+	if !ok || !s.synced {
+		return nil, nil
+	}
+	pending, err := m.src.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	m.prune(s, pending)
+	if pending > s.Next {
+		m.log.Warn("nonce advanced by another sender", zap.String("signer", addr.Hex()), zap.Uint64("local_next", s.Next), zap.Uint64("pending", pending))
+		s.Next, s.Gaps = pending, nil
+		m.persist(addr, s)
+		return nil, nil
+	}
+
+	var found []uint64
+	for n := pending; n < s.Next; n++ {
+		if containsNonce(s.Gaps, n) {
+			continue
+		}
+		if hash, ok := s.InFlight[n]; ok {
+			if hash == (common.Hash{}) {
+				continue
+			}
+			_, _, err := m.src.TransactionByHash(ctx, hash)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				return found, err
+			}
+			delete(s.InFlight, n)
+		}
+		s.Gaps = insertGap(s.Gaps, n)
+		found = append(found, n)
+	}
+	if len(found) > 0 {
+		m.log.Warn("nonce gap detected", zap.String("signer", addr.Hex()), zap.Uint64s("nonces", found))
+	}
+	m.persist(addr, s)
+	return found, nil
+}
+
+func (m *nonceManager) prune(s *signerNonces, pending uint64) {
+	for n := range s.InFlight {
+		if n < pending {
+			delete(s.InFlight, n)
+		}
+	}
+	kept := s.Gaps[:0]
+	for _, n := range s.Gaps {
+		if n >= pending {
+			kept = append(kept, n)
+		}
+	}
+	s.Gaps = kept
+}
+
+func (m *nonceManager) persist(addr common.Address, s *signerNonces) {
+	if m.store == nil {
+		return
+	}
+	state := NonceState{Next: s.Next, Gaps: append([]uint64(nil), s.Gaps...), InFlight: make(map[uint64]common.Hash, len(s.InFlight))}
+	for n, hash := range s.InFlight {
+		state.InFlight[n] = hash
+	}
+	m.store.PutNonce(addr, state)
+}
+
+func insertGap(gaps []uint64, n uint64) []uint64 {
+	if containsNonce(gaps, n) {
+		return gaps
+	}
+	gaps = append(gaps, n)
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps
+}
+
+func containsNonce(gaps []uint64, n uint64) bool {
+	for _, g := range gaps {
+		if g == n {
+			return true
+		}
+	}
+	return false
+}
+
+// isNonceConflict reports node errors that mean the nonce used is already
+// taken or out of order, so local state needs a resync.
+func isNonceConflict(err error) bool {
+	err = classify(err)
+	if errors.Is(err, ErrNonceTooLow) || errors.Is(err, ErrNonceTooHigh) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "replacement transaction underpriced")
+}
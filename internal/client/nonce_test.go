@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+type fakeNonces struct {
+	pending uint64
+	known   map[common.Hash]bool
+}
+
+func (f *fakeNonces) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return f.pending, nil
+}
+
+func (f *fakeNonces) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if f.known[hash] {
+		return nil, true, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func nonceHash(n uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(n + 100))
+}
+
+type memNonceStore map[common.Address]NonceState
+
+func (m memNonceStore) Nonce(signer common.Address) (NonceState, bool) {
+	state, ok := m[signer]
+	return state, ok
+}
+
+func (m memNonceStore) PutNonce(signer common.Address, state NonceState) {
+	m[signer] = state
+}
+
+func TestNonceManagerReusesReleasedNonces(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x01")
+	src := &fakeNonces{pending: 5, known: map[common.Hash]bool{}}
+	m := newNonceManager(src, zap.NewNop())
+
+	for want := uint64(5); want <= 7; want++ {
+		got, err := m.acquire(ctx, addr)
+		if err != nil || got != want {
+			t.Fatalf("acquire: got %d, %v want %d", got, err, want)
+		}
+	}
+	m.release(addr, 6)
+	if got, _ := m.acquire(ctx, addr); got != 6 {
+		t.Fatalf("expected released nonce 6 to be reused, got %d", got)
+	}
+	m.release(addr, 7)
+	if got, _ := m.acquire(ctx, addr); got != 7 {
+		t.Fatalf("expected top nonce 7 to be handed out again, got %d", got)
+	}
+	if got, _ := m.acquire(ctx, addr); got != 8 {
+		t.Fatalf("expected fresh nonce 8, got %d", got)
+	}
+}
+
+func TestNonceManagerRestoresAndDetectsGaps(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x01")
+	src := &fakeNonces{pending: 3, known: map[common.Hash]bool{}}
+	store := memNonceStore{}
+
+	m := newNonceManager(src, zap.NewNop())
+	m.setStore(store)
+	for i := 0; i < 3; i++ {
+		n, err := m.acquire(ctx, addr)
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		hash := nonceHash(n)
+		m.sent(addr, n, hash)
+		src.known[hash] = true
+	}
+
+	// A restart picks up the persisted nonce rather than the node's view.
+	restarted := newNonceManager(src, zap.NewNop())
+	restarted.setStore(store)
+	if got, _ := restarted.acquire(ctx, addr); got != 6 {
+		t.Fatalf("expected restart to continue at 6, got %d", got)
+	}
+	restarted.release(addr, 6)
+
+	// Nonce 3 mined, nonce 4 was dropped, nonce 5 is queued behind it.
+	src.pending = 4
+	delete(src.known, nonceHash(4))
+	gaps, err := restarted.checkGaps(ctx, addr)
+	if err != nil {
+		t.Fatalf("check gaps: %v", err)
+	}
+	if len(gaps) != 1 || gaps[0] != 4 {
+		t.Fatalf("expected gap at nonce 4, got %v", gaps)
+	}
+	if got, _ := restarted.acquire(ctx, addr); got != 4 {
+		t.Fatalf("expected gap nonce 4 to be filled first, got %d", got)
+	}
+
+	// Another sender used nonces past ours.
+	src.pending = 9
+	if _, err := restarted.checkGaps(ctx, addr); err != nil {
+		t.Fatalf("check gaps: %v", err)
+	}
+	if got, _ := restarted.acquire(ctx, addr); got != 9 {
+		t.Fatalf("expected to adopt pending nonce 9, got %d", got)
+	}
+}
+
+func TestNonceManagerResync(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x01")
+	src := &fakeNonces{pending: 10}
+	m := newNonceManager(src, zap.NewNop())
+	if _, err := m.acquire(ctx, addr); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	src.pending = 12
+	if err := m.resync(ctx, addr); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if got, _ := m.acquire(ctx, addr); got != 12 {
+		t.Fatalf("expected nonce 12 after resync, got %d", got)
+	}
+}
+
+// knownEth rejects every broadcast as a transaction it already has, as a
+// node does when an earlier send reached the pool through another endpoint.
+type knownEth struct {
+	sent int
+}
+
+func (k *knownEth) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	k.sent++
+	return common.Hash{}, errors.New("already known")
+}
+
+func TestSendTxTreatsAlreadyKnownAsSent(t *testing.T) {
+	ctx := context.Background()
+	svc := &knownEth{}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer srv.Stop()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	signer := NewKeySigner(key)
+	store := memNonceStore{}
+	c := &EthClient{
+		rpc:     newPool(map[string]*ethclient.Client{"inproc": ethclient.NewClient(rpc.DialInProc(srv))}, zap.NewNop()),
+		log:     zap.NewNop(),
+		chainID: big.NewInt(8453),
+		nonces:  newNonceManager(&fakeNonces{pending: 5}, zap.NewNop()),
+	}
+	c.UseNonceStore(store)
+
+	hash, err := c.sendTx(ctx, signer, []byte{0x01}, 100000, txFees{feeCap: big.NewInt(10)})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if svc.sent != 1 {
+		t.Fatalf("expected a single broadcast and no re-sign, got %d", svc.sent)
+	}
+	if state := store[signer.Address()]; state.InFlight[5] != hash || hash == (common.Hash{}) {
+		t.Fatalf("expected nonce 5 recorded as sent with %s, got %+v", hash.Hex(), state)
+	}
+	if got, _ := c.nonces.acquire(ctx, signer.Address()); got != 6 {
+		t.Fatalf("expected next nonce 6, got %d", got)
+	}
+}
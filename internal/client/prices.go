@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const aggregatorABIJSON = `[
+  {
+    "type": "function",
+    "name": "decimals",
+    "stateMutability": "view",
+    "inputs": [],
+    "outputs": [{ "name": "", "type": "uint8" }]
+  },
+  {
+    "type": "function",
+    "name": "latestRoundData",
+    "stateMutability": "view",
+    "inputs": [],
+    "outputs": [
+      { "name": "roundId", "type": "uint80" },
+      { "name": "answer", "type": "int256" },
+      { "name": "startedAt", "type": "uint256" },
+      { "name": "updatedAt", "type": "uint256" },
+      { "name": "answeredInRound", "type": "uint80" }
+    ]
+  },
+  {
+    "type": "function",
+    "name": "getRoundData",
+    "stateMutability": "view",
+    "inputs": [{ "name": "_roundId", "type": "uint80" }],
+    "outputs": [
+      { "name": "roundId", "type": "uint80" },
+      { "name": "answer", "type": "int256" },
+      { "name": "startedAt", "type": "uint256" },
+      { "name": "updatedAt", "type": "uint256" },
+      { "name": "answeredInRound", "type": "uint80" }
+    ]
+  }
+]`
+
+var (
+	ErrPriceUnavailable = errors.New("price unavailable")
+	ErrPriceStale       = errors.New("price is stale")
+	ErrPriceDeviation   = errors.New("price moved too far from the previous round")
+)
+
+// PriceQuote is the latest answer of a Chainlink-style USD aggregator.
+// Previous is the answer of the round before, nil when the feed has no
+// earlier round.
+type PriceQuote struct {
+	Feed      common.Address
+	Decimals  uint8
+	RoundID   *big.Int
+	Answer    *big.Int
+	UpdatedAt time.Time
+	Previous  *big.Int
+}
+
+// Check rejects a quote older than maxAge or one that moved more than
+// maxDeviation (a fraction, 0.1 is 10%) from the previous round. Zero
+// disables either check.
+func (q PriceQuote) Check(now time.Time, maxAge time.Duration, maxDeviation float64) error {
+	if maxAge > 0 && now.Sub(q.UpdatedAt) > maxAge {
+		return fmt.Errorf("%w: feed %s updated %s ago, max %s", ErrPriceStale, q.Feed.Hex(), now.Sub(q.UpdatedAt).Round(time.Second), maxAge)
+	}
+	if maxDeviation > 0 && q.Previous != nil {
+		diff := new(big.Float).SetInt(new(big.Int).Sub(q.Answer, q.Previous))
+		moved, _ := new(big.Float).Quo(diff.Abs(diff), new(big.Float).SetInt(q.Previous)).Float64()
+		if moved > maxDeviation {
+			return fmt.Errorf("%w: feed %s moved %.2f%%, max %.2f%%", ErrPriceDeviation, q.Feed.Hex(), moved*100, maxDeviation*100)
+		}
+	}
+	return nil
+}
+
+type priceRound struct {
+	id        *big.Int
+	answer    *big.Int
+	updatedAt *big.Int
+	answered  *big.Int
+}
+
+// LatestPrice reads the latest round of the aggregator at feed along with
+// the round before it. Rounds that are incomplete, carried over from an
+// earlier round or not positive are reported as ErrPriceUnavailable;
+// staleness and deviation limits are left to the caller.
+func (c *EthClient) LatestPrice(ctx context.Context, feed common.Address) (PriceQuote, error) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABIJSON))
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	res, err := c.callFeed(ctx, parsed, feed, "decimals")
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	out, err := parsed.Unpack("decimals", res)
+	if err != nil {
+		return PriceQuote{}, fmt.Errorf("feed %s decimals: %w", feed.Hex(), err)
+	}
+	decimals, ok := out[0].(uint8)
+	if !ok {
+		return PriceQuote{}, fmt.Errorf("invalid decimals type %T", out[0])
+	}
+
+	latest, err := c.priceRound(ctx, parsed, feed, "latestRoundData")
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	if latest.answer.Sign() <= 0 || latest.updatedAt.Sign() == 0 || latest.answered.Cmp(latest.id) < 0 {
+		return PriceQuote{}, fmt.Errorf("%w: feed %s round %s is incomplete", ErrPriceUnavailable, feed.Hex(), latest.id)
+	}
+	quote := PriceQuote{
+		Feed:      feed,
+		Decimals:  decimals,
+		RoundID:   latest.id,
+		Answer:    latest.answer,
+		UpdatedAt: time.Unix(latest.updatedAt.Int64(), 0),
+	}
+
+	if prevID := new(big.Int).Sub(latest.id, big.NewInt(1)); prevID.Sign() > 0 {
+		prev, err := c.priceRound(ctx, parsed, feed, "getRoundData", prevID)
+		if err == nil && prev.answer.Sign() > 0 {
+			quote.Previous = prev.answer
+		}
+	}
+	return quote, nil
+}
+
+func (c *EthClient) priceRound(ctx context.Context, parsed abi.ABI, feed common.Address, method string, args ...interface{}) (priceRound, error) {
+	res, err := c.callFeed(ctx, parsed, feed, method, args...)
+	if err != nil {
+		return priceRound{}, err
+	}
+	out, err := parsed.Unpack(method, res)
+	if err != nil {
+		return priceRound{}, fmt.Errorf("%w: feed %s %s: %v", ErrPriceUnavailable, feed.Hex(), method, err)
+	}
+	id, okID := out[0].(*big.Int)
+	answer, okAnswer := out[1].(*big.Int)
+	updatedAt, okUpdated := out[3].(*big.Int)
+	answered, okAnswered := out[4].(*big.Int)
+	if !okID || !okAnswer || !okUpdated || !okAnswered {
+		return priceRound{}, fmt.Errorf("unexpected %s output", method)
+	}
+	return priceRound{id: id, answer: answer, updatedAt: updatedAt, answered: answered}, nil
+}
+
+func (c *EthClient) callFeed(ctx context.Context, parsed abi.ABI, feed common.Address, method string, args ...interface{}) ([]byte, error) {
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.rpc.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: feed %s %s: %v", ErrPriceUnavailable, feed.Hex(), method, err)
+	}
+	return res, nil
+}
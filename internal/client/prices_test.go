@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+type mockRound struct {
+	answer    int64
+	updatedAt int64
+	answered  int64
+}
+
+// mockAggregator answers eth_call like a Chainlink aggregator with 8
+// decimals whose latest round is the highest key in rounds.
+type mockAggregator struct {
+	abi    abi.ABI
+	rounds map[int64]mockRound
+	latest int64
+}
+
+func (m *mockAggregator) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	input, _ := args["input"].(string)
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	method, err := m.abi.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	id := m.latest
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(8))
+	case "getRoundData":
+		in, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return nil, err
+		}
+		id = in[0].(*big.Int).Int64()
+	}
+	r, ok := m.rounds[id]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return method.Outputs.Pack(big.NewInt(id), big.NewInt(r.answer), big.NewInt(r.updatedAt), big.NewInt(r.updatedAt), big.NewInt(r.answered))
+}
+
+func TestLatestPriceFromMockAggregator(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABIJSON))
+	if err != nil {
+		t.Fatalf("abi: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	feed := common.HexToAddress("0x71041dddad3595F9CEd3DcCFBe3D1F4b0a16Bb70")
+	svc := &mockAggregator{abi: parsed, latest: 10, rounds: map[int64]mockRound{
+		9:  {answer: 1990_00000000, updatedAt: now.Add(-2 * time.Hour).Unix(), answered: 9},
+		10: {answer: 2000_00000000, updatedAt: now.Add(-10 * time.Minute).Unix(), answered: 10},
+	}}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer srv.Stop()
+	c := &EthClient{
+		rpc: newPool(map[string]*ethclient.Client{"inproc": ethclient.NewClient(rpc.DialInProc(srv))}, zap.NewNop()),
+		log: zap.NewNop(),
+	}
+
+	quote, err := c.LatestPrice(context.Background(), feed)
+	if err != nil {
+		t.Fatalf("latest price: %v", err)
+	}
+	if quote.Decimals != 8 || quote.Answer.Int64() != 2000_00000000 || quote.Previous == nil || quote.Previous.Int64() != 1990_00000000 {
+		t.Fatalf("unexpected quote %+v", quote)
+	}
+	if err := quote.Check(now, time.Hour, 0.05); err != nil {
+		t.Fatalf("expected fresh quote to pass: %v", err)
+	}
+	if err := quote.Check(now, 5*time.Minute, 0.05); !errors.Is(err, ErrPriceStale) {
+		t.Fatalf("expected stale quote, got %v", err)
+	}
+	if err := quote.Check(now, time.Hour, 0.001); !errors.Is(err, ErrPriceDeviation) {
+		t.Fatalf("expected deviation failure, got %v", err)
+	}
+
+	svc.rounds[10] = mockRound{answer: 2000_00000000, updatedAt: now.Unix(), answered: 9}
+	if _, err := c.LatestPrice(context.Background(), feed); !errors.Is(err, ErrPriceUnavailable) {
+		t.Fatalf("expected carried over round to be unavailable, got %v", err)
+	}
+	svc.rounds[10] = mockRound{answer: 0, updatedAt: now.Unix(), answered: 10}
+	if _, err := c.LatestPrice(context.Background(), feed); !errors.Is(err, ErrPriceUnavailable) {
+		t.Fatalf("expected zero answer to be unavailable, got %v", err)
+	}
+}
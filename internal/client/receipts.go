@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type Receipt struct {
+	TxHash      common.Hash
+	Succeeded   bool
+	GasUsed     uint64
+	BlockNumber uint64
+	BlockHash   common.Hash
+	// Batch is the BatchExecuted event emitted by the transaction, if any.
+	Batch *BatchExecutedEvent
+}
+
+// ProcessedIDs returns the request ids the contract reports as executed in
+// this transaction.
+func (r Receipt) ProcessedIDs() []uint64 {
+	if r.Batch == nil {
+		return nil
+	}
+	return uint64IDs(r.Batch.IDsProcessed)
+}
+
+// Receipt returns the mined receipt for hash, or nil while the transaction is
+// still pending.
+func (c *EthClient) Receipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	receipt, err := c.rpc.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return receiptFrom(c.abi, c.contract, receipt)
+}
+
+func receiptFrom(parsed abi.ABI, contract common.Address, receipt *types.Receipt) (*Receipt, error) {
+	out := &Receipt{
+		TxHash:    receipt.TxHash,
+		Succeeded: receipt.Status == types.ReceiptStatusSuccessful,
+		GasUsed:   receipt.GasUsed,
+		BlockHash: receipt.BlockHash,
+	}
+	if receipt.BlockNumber != nil {
+		out.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	batchID := parsed.Events["BatchExecuted"].ID
+	for _, lg := range receipt.Logs {
+		if lg == nil || lg.Address != contract || len(lg.Topics) == 0 || lg.Topics[0] != batchID {
+			continue
+		}
+		evt, err := decodeEvent(parsed, *lg)
+		if err != nil {
+			return nil, err
+		}
+		batch := evt.(BatchExecutedEvent)
+		out.Batch = &batch
+	}
+	return out, nil
+}
+
+func uint64IDs(ids []*big.Int) []uint64 {
+	out := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id != nil && id.IsUint64() {
+			out = append(out, id.Uint64())
+		}
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrSignerMismatch = errors.New("remote signer returned a different transaction")
+
+// RemoteSigner asks an external signer such as Clef or web3signer to sign,
+// so the key never lives on the guardd host. Clef serves
+// account_signTransaction; geth and web3signer serve eth_signTransaction.
+type RemoteSigner struct {
+	client *rpc.Client
+	method string
+	addr   common.Address
+}
+
+// signTxArgs is the transaction object both signing methods accept.
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                hexutil.Big     `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+func DialRemoteSigner(url string, addr common.Address, method string) (*RemoteSigner, error) {
+	switch method {
+	case "eth_signTransaction", "account_signTransaction":
+	default:
+		return nil, fmt.Errorf("unsupported signer method %q", method)
+	}
+	client, err := rpc.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{client: client, method: method, addr: addr}, nil
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.addr
+}
+
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:    s.addr,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	var res json.RawMessage
+	if err := s.client.CallContext(ctx, &res, s.method, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.method, err)
+	}
+	raw, err := rawSignedTx(res)
+	if err != nil {
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("decode signed tx: %w", err)
+	}
+	if err := checkSigned(tx, signed, s.addr, chainID); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// rawSignedTx accepts both the {raw, tx} object geth and Clef return and the
+// bare hex string web3signer returns.
+func rawSignedTx(res json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(res, &raw); err == nil {
+		return raw, nil
+	}
+	var obj struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(res, &obj); err != nil || len(obj.Raw) == 0 {
+		return nil, fmt.Errorf("unexpected signer response %s", res)
+	}
+	return obj.Raw, nil
+}
+
+// checkSigned makes sure the signer signed exactly what was asked, as the
+// expected account, before the transaction is broadcast.
+func checkSigned(want, got *types.Transaction, from common.Address, chainID *big.Int) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), got)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignerMismatch, err)
+	}
+	if sender != from {
+		return fmt.Errorf("%w: signed by %s, want %s", ErrSignerMismatch, sender.Hex(), from.Hex())
+	}
+	same := got.Type() == want.Type() &&
+		got.Nonce() == want.Nonce() &&
+		got.Gas() == want.Gas() &&
+		got.To() != nil && want.To() != nil && *got.To() == *want.To() &&
+		got.Value().Cmp(want.Value()) == 0 &&
+		got.GasFeeCap().Cmp(want.GasFeeCap()) == 0 &&
+		got.GasTipCap().Cmp(want.GasTipCap()) == 0 &&
+		bytes.Equal(got.Data(), want.Data())
+	if !same {
+		return fmt.Errorf("%w: nonce %d, to %v", ErrSignerMismatch, got.Nonce(), got.To())
+	}
+	return nil
+}
+
+func closeSigner(s Signer) {
+	if closer, ok := s.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
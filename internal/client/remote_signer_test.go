@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeSignerService stands in for Clef or web3signer. tamper bumps the
+// nonce before signing to mimic a misbehaving signer.
+type fakeSignerService struct {
+	key    *ecdsa.PrivateKey
+	tamper bool
+	bare   bool
+}
+
+func (f *fakeSignerService) SignTransaction(args signTxArgs) (interface{}, error) {
+	nonce := uint64(args.Nonce)
+	if f.tamper {
+		nonce++
+	}
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     nonce,
+		To:        args.To,
+		Gas:       uint64(args.Gas),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Value:     args.Value.ToInt(),
+		Data:      args.Data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(args.ChainID.ToInt()), f.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if f.bare {
+		return hexutil.Bytes(raw), nil
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": signed}, nil
+}
+
+func startFakeSigner(t *testing.T, svc *fakeSignerService) string {
+	t.Helper()
+	srv := rpc.NewServer()
+	for _, ns := range []string{"eth", "account"} {
+		if err := srv.RegisterName(ns, svc); err != nil {
+			t.Fatalf("register %s: %v", ns, err)
+		}
+	}
+	httpSrv := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		httpSrv.Close()
+		srv.Stop()
+	})
+	return httpSrv.URL
+}
+
+func TestRemoteSignerSignsAndVerifies(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(8453)
+	to := common.HexToAddress("0x01")
+	unsigned := types.NewTx(&types.DynamicFeeTx{
+		ChainID: chainID, Nonce: 7, To: &to, Gas: 90000,
+		GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2_000_000_000),
+		Value: big.NewInt(0), Data: []byte{0xde, 0xad},
+	})
+
+	cases := []struct {
+		name    string
+		method  string
+		svc     *fakeSignerService
+		signAs  common.Address
+		wantErr error
+	}{
+		{"clef", "account_signTransaction", &fakeSignerService{key: key}, addr, nil},
+		{"web3signerBareHex", "eth_signTransaction", &fakeSignerService{key: key, bare: true}, addr, nil},
+		{"tamperedNonce", "eth_signTransaction", &fakeSignerService{key: key, tamper: true}, addr, ErrSignerMismatch},
+		{"wrongAccount", "eth_signTransaction", &fakeSignerService{key: key}, common.HexToAddress("0x02"), ErrSignerMismatch},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := DialRemoteSigner(startFakeSigner(t, tc.svc), tc.signAs, tc.method)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer s.Close()
+
+			signed, err := s.SignTx(context.Background(), unsigned, chainID)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+			if err != nil || sender != addr || signed.Nonce() != 7 {
+				t.Fatalf("unexpected signed tx from %s nonce %d: %v", sender.Hex(), signed.Nonce(), err)
+			}
+		})
+	}
+
+	if _, err := DialRemoteSigner("http://127.0.0.1:1", addr, "personal_sign"); err == nil {
+		t.Fatalf("expected unsupported method to be rejected")
+	}
+}
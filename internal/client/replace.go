@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// minBumpPercent is the price bump geth-based txpools require before they
+// accept a replacement for the same nonce.
+const minBumpPercent = 10
+
+var (
+	ErrTxNotPending   = errors.New("transaction is not pending")
+	ErrTxDropped      = errors.New("transaction is unknown to the node")
+	ErrFeeCapReached  = errors.New("replacement fee would exceed max fee per gas")
+	ErrUnknownAccount = errors.New("transaction was not sent by a configured key")
+)
+
+type Replacement struct {
+	Hash      common.Hash
+	Nonce     uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// ReplaceTx re-signs a pending transaction with the same nonce, gas and data
+// and fees raised by the configured bump percentage, or to the current
+// suggestion if that is higher. Fees never exceed the max fee per gas;
+// ErrFeeCapReached is returned once no compliant bump fits under it.
+func (c *EthClient) ReplaceTx(ctx context.Context, hash common.Hash) (Replacement, error) {
+	tx, pending, err := c.rpc.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return Replacement{}, ErrTxDropped
+	}
+	if err == nil && !pending {
+		return Replacement{}, ErrTxNotPending
+	}
+	if err != nil {
+		return Replacement{}, err
+	}
+
+	signer := types.LatestSignerForChainID(c.chainID)
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return Replacement{}, err
+	}
+	s, err := c.signerFor(from)
+	if err != nil {
+		return Replacement{}, err
+	}
+
+	var replacement *types.Transaction
+	if tx.Type() == types.DynamicFeeTxType {
+		suggestedTip, err := c.rpc.SuggestGasTipCap(ctx)
+		if err != nil {
+			return Replacement{}, err
+		}
+		suggestedFee, err := c.rpc.SuggestGasPrice(ctx)
+		if err != nil {
+			return Replacement{}, err
+		}
+		tip, feeCap, err := bumpFees(tx.GasTipCap(), tx.GasFeeCap(), suggestedTip, suggestedFee, c.bumpPercent, c.maxFee)
+		if err != nil {
+			return Replacement{}, err
+		}
+		replacement = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   c.chainID,
+			Nonce:     tx.Nonce(),
+			To:        tx.To(),
+			Gas:       tx.Gas(),
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		})
+	} else {
+		suggested, err := c.rpc.SuggestGasPrice(ctx)
+		if err != nil {
+			return Replacement{}, err
+		}
+		_, price, err := bumpFees(tx.GasPrice(), tx.GasPrice(), suggested, suggested, c.bumpPercent, c.maxFee)
+		if err != nil {
+			return Replacement{}, err
+		}
+		replacement = types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			To:       tx.To(),
+			Gas:      tx.Gas(),
+			GasPrice: price,
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	}
+
+	if _, err := c.checkFee(ctx, replacement); err != nil {
+		if errors.Is(err, ErrFeeAboveCeiling) {
+			return Replacement{}, fmt.Errorf("%w: %v", ErrFeeCapReached, err)
+		}
+		return Replacement{}, err
+	}
+
+	signed, err := s.SignTx(ctx, replacement, c.chainID)
+	if err != nil {
+		return Replacement{}, err
+	}
+	if err := c.rpc.SendTransaction(ctx, signed); err != nil && !isAlreadyKnown(err) {
+		if isNonceTooLow(err) {
+			return Replacement{}, ErrTxNotPending
+		}
+		return Replacement{}, classify(err)
+	}
+	c.nonces.sent(from, signed.Nonce(), signed.Hash())
+	return Replacement{Hash: signed.Hash(), Nonce: signed.Nonce(), GasTipCap: signed.GasTipCap(), GasFeeCap: signed.GasFeeCap()}, nil
+}
+
+// bumpFees returns the replacement tip and fee cap. Each is at least
+// percent above the previous value and no lower than the current
+// suggestion; a non-zero maxFee caps the fee cap.
+func bumpFees(tip, feeCap, suggestedTip, suggestedFee *big.Int, percent uint64, maxFee *big.Int) (*big.Int, *big.Int, error) {
+	minTip := bumpBy(tip, percent)
+	minFee := bumpBy(feeCap, percent)
+
+	newTip := maxBig(minTip, suggestedTip)
+	newFee := maxBig(minFee, suggestedFee)
+	if newFee.Cmp(newTip) < 0 {
+		newFee = new(big.Int).Set(newTip)
+	}
+	if maxFee != nil && maxFee.Sign() > 0 && newFee.Cmp(maxFee) > 0 {
+		newFee = new(big.Int).Set(maxFee)
+		if newTip.Cmp(newFee) > 0 {
+			newTip = new(big.Int).Set(newFee)
+		}
+	}
+	if newFee.Cmp(minFee) < 0 || newTip.Cmp(minTip) < 0 {
+		return nil, nil, fmt.Errorf("%w: need fee cap %s, max %s", ErrFeeCapReached, minFee, maxFee)
+	}
+	return newTip, newFee, nil
+}
+
+// bumpBy returns v raised by percent, rounded up so small values still move.
+func bumpBy(v *big.Int, percent uint64) *big.Int {
+	out := new(big.Int).Mul(v, new(big.Int).SetUint64(100+percent))
+	out.Add(out, big.NewInt(99))
+	return out.Div(out, big.NewInt(100))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return new(big.Int).Set(b)
+	}
+	return new(big.Int).Set(a)
+}
+
+func (c *EthClient) signerFor(from common.Address) (Signer, error) {
+	for _, s := range []Signer{c.guardian, c.executor} {
+		if s != nil && s.Address() == from {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAccount, from.Hex())
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestBumpFees(t *testing.T) {
+	gwei := func(v int64) *big.Int { return new(big.Int).Mul(big.NewInt(v), big.NewInt(1_000_000_000)) }
+	cases := []struct {
+		name             string
+		tip, fee         *big.Int
+		sugTip, sugFee   *big.Int
+		max              *big.Int
+		wantTip, wantFee *big.Int
+		wantErr          error
+	}{
+		{"bumpsByPercent", gwei(1), gwei(10), gwei(1), gwei(5), gwei(100), big.NewInt(1_150_000_000), big.NewInt(11_500_000_000), nil},
+		{"followsHigherSuggestion", gwei(1), gwei(10), gwei(3), gwei(30), gwei(100), gwei(3), gwei(30), nil},
+		{"clampsToMax", gwei(1), gwei(10), gwei(2), gwei(50), gwei(20), gwei(2), gwei(20), nil},
+		{"noCap", gwei(1), gwei(10), gwei(2), gwei(500), big.NewInt(0), gwei(2), gwei(500), nil},
+		{"capReached", gwei(1), gwei(19), gwei(1), gwei(5), gwei(20), nil, nil, ErrFeeCapReached},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tip, fee, err := bumpFees(tc.tip, tc.fee, tc.sugTip, tc.sugFee, 15, tc.max)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tip.Cmp(tc.wantTip) != 0 || fee.Cmp(tc.wantFee) != 0 {
+				t.Fatalf("got tip %s fee %s, want tip %s fee %s", tip, fee, tc.wantTip, tc.wantFee)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type SignerStatus struct {
+	Role       string
+	RoleHash   common.Hash
+	Configured bool
+	Address    common.Address
+	HasRole    bool
+	Balance    *big.Int
+}
+
+func (c *EthClient) HasRole(ctx context.Context, role common.Hash, account common.Address) (bool, error) {
+	data, err := c.abi.Pack("hasRole", role, account)
+	if err != nil {
+		return false, err
+	}
+	res, err := c.rpc.CallContract(ctx, ethereum.CallMsg{To: &c.contract, Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+	decoded, err := c.abi.Unpack("hasRole", res)
+	if err != nil {
+		return false, err
+	}
+	ok, isBool := decoded[0].(bool)
+	if !isBool {
+		return false, fmt.Errorf("invalid hasRole type")
+	}
+	return ok, nil
+}
+
+// SignerStatus reports, for the guardian and executor roles, which account
+// signs for it, whether that account holds the role on the contract and its
+// ETH balance for gas.
+func (c *EthClient) SignerStatus(ctx context.Context) ([]SignerStatus, error) {
+	roles := []struct {
+		name   string
+		role   common.Hash
+		signer Signer
+	}{
+		{"guardian", GuardianRole, c.guardian},
+		{"executor", ExecutorRole, c.executor},
+	}
+	out := make([]SignerStatus, 0, len(roles))
+	for _, r := range roles {
+		status := SignerStatus{Role: r.name, RoleHash: r.role}
+		if r.signer != nil {
+			status.Configured = true
+			status.Address = r.signer.Address()
+			has, err := c.HasRole(ctx, r.role, status.Address)
+			if err != nil {
+				return nil, fmt.Errorf("%s hasRole: %w", r.name, err)
+			}
+			status.HasRole = has
+			balance, err := c.rpc.BalanceAt(ctx, status.Address, nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s balance: %w", r.name, err)
+			}
+			status.Balance = balance
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
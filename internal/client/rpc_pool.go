@@ -0,0 +1,433 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNoEndpoints    = errors.New("no rpc endpoints configured")
+	ErrQuorumMismatch = errors.New("rpc quorum disagreement")
+)
+
+const latencyAlpha = 0.2
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// endpoint tracks one provider's latency and error rate as exponentially
+// weighted averages, plus a circuit breaker that takes it out of rotation
+// after consecutive transport failures.
+type endpoint struct {
+	name   string
+	url    string
+	client *ethclient.Client
+
+	mu          sync.Mutex
+	latency     time.Duration
+	errorRate   float64
+	consecutive int
+	state       breakerState
+	openUntil   time.Time
+}
+
+type EndpointHealth struct {
+	Name      string
+	Latency   time.Duration
+	ErrorRate float64
+	Breaker   string
+}
+
+// allow reports whether a call may be sent. An open breaker lets a single
+// trial call through once its cooldown has passed.
+func (e *endpoint) allow(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch e.state {
+	case breakerOpen:
+		if now.Before(e.openUntil) {
+			return false
+		}
+		e.state = breakerHalfOpen
+		return true
+	default:
+		return true
+	}
+}
+
+func (e *endpoint) score() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	latency := float64(e.latency)
+	if latency == 0 {
+		latency = float64(time.Millisecond)
+	}
+	return latency * (1 + 10*e.errorRate)
+}
+
+// record updates health after a call and returns the breaker state change, if
+// any, for logging.
+func (e *endpoint) record(took time.Duration, failed bool, threshold int, cooldown time.Duration) (breakerState, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.latency == 0 {
+		e.latency = took
+	} else {
+		e.latency = time.Duration(latencyAlpha*float64(took) + (1-latencyAlpha)*float64(e.latency))
+	}
+	sample := 0.0
+	if failed {
+		sample = 1
+	}
+	e.errorRate = latencyAlpha*sample + (1-latencyAlpha)*e.errorRate
+
+	prev := e.state
+	if !failed {
+		e.consecutive = 0
+		e.state = breakerClosed
+		return e.state, prev != e.state
+	}
+	e.consecutive++
+	if e.state == breakerHalfOpen || (threshold > 0 && e.consecutive >= threshold) {
+		e.state = breakerOpen
+		e.openUntil = time.Now().Add(cooldown)
+	}
+	return e.state, prev != e.state
+}
+
+func (e *endpoint) health() EndpointHealth {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return EndpointHealth{Name: e.name, Latency: e.latency, ErrorRate: e.errorRate, Breaker: e.state.String()}
+}
+
+// rpcPool fronts several HTTP endpoints with the subset of the ethclient API
+// EthClient uses. Each call goes to the healthiest endpoint and fails over to
+// the next one on transport errors; JSON-RPC errors are returned as is since
+// another provider would answer the same way.
+type rpcPool struct {
+	endpoints []*endpoint
+	log       *zap.Logger
+	threshold int
+	cooldown  time.Duration
+}
+
+func dialPool(urls []string, threshold int, cooldown time.Duration, log *zap.Logger) (*rpcPool, error) {
+	if len(urls) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	p := &rpcPool{log: log, threshold: threshold, cooldown: cooldown}
+	for _, raw := range urls {
+		cl, err := ethclient.Dial(raw)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("dial %s: %w", endpointName(raw), err)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{name: endpointName(raw), url: raw, client: cl})
+	}
+	return p, nil
+}
+
+func newPool(clients map[string]*ethclient.Client, log *zap.Logger) *rpcPool {
+	p := &rpcPool{log: log, threshold: 3, cooldown: 30 * time.Second}
+	names := make([]string, 0, len(clients))
+	for name := range clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p.endpoints = append(p.endpoints, &endpoint{name: name, client: clients[name]})
+	}
+	return p
+}
+
+// endpointName strips paths and query strings, which usually carry provider
+// API keys, so endpoints can be logged safely.
+func endpointName(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "endpoint"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func (p *rpcPool) Close() {
+	for _, ep := range p.endpoints {
+		ep.client.Close()
+	}
+}
+
+func (p *rpcPool) Health() []EndpointHealth {
+	out := make([]EndpointHealth, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		out = append(out, ep.health())
+	}
+	return out
+}
+
+func (p *rpcPool) ordered() []*endpoint {
+	return orderEndpoints(p.endpoints)
+}
+
+// orderEndpoints returns endpoints whose breaker admits a call, healthiest
+// first. If every breaker is open the full list is returned so calls still
+// go out.
+func orderEndpoints(endpoints []*endpoint) []*endpoint {
+	now := time.Now()
+	allowed := make([]*endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.allow(now) {
+			allowed = append(allowed, ep)
+		}
+	}
+	if len(allowed) == 0 {
+		allowed = append(allowed, endpoints...)
+	}
+	sort.SliceStable(allowed, func(i, j int) bool { return allowed[i].score() < allowed[j].score() })
+	return allowed
+}
+
+func (p *rpcPool) observe(ctx context.Context, ep *endpoint, start time.Time, err error) bool {
+	failed := isEndpointFailure(ctx, err)
+	if ctx.Err() != nil && err != nil {
+		return false
+	}
+	state, changed := ep.record(time.Since(start), failed, p.threshold, p.cooldown)
+	if changed {
+		p.log.Warn("rpc endpoint breaker changed", zap.String("endpoint", ep.name), zap.String("state", state.String()))
+	}
+	return failed
+}
+
+func (p *rpcPool) do(ctx context.Context, fn func(*ethclient.Client) error) error {
+	var lastErr error
+	for _, ep := range p.ordered() {
+		start := time.Now()
+		err := fn(ep.client)
+		if !p.observe(ctx, ep, start, err) {
+			return err
+		}
+		p.log.Warn("rpc call failed, trying next endpoint", zap.String("endpoint", ep.name), zap.Error(err))
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = ErrNoEndpoints
+	}
+	return lastErr
+}
+
+// each runs fn against up to n distinct endpoints, healthiest first, and
+// returns the index-aligned results. Endpoints with transport failures are
+// skipped; fewer than n answers is an error.
+func each[T any](ctx context.Context, p *rpcPool, n int, fn func(*ethclient.Client) (T, error)) ([]T, error) {
+	out := make([]T, 0, n)
+	var lastErr error
+	for _, ep := range p.ordered() {
+		if len(out) == n {
+			break
+		}
+		start := time.Now()
+		v, err := fn(ep.client)
+		if p.observe(ctx, ep, start, err) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if len(out) < n {
+		if lastErr == nil {
+			lastErr = ErrNoEndpoints
+		}
+		return nil, fmt.Errorf("quorum needs %d endpoints, got %d: %w", n, len(out), lastErr)
+	}
+	return out, nil
+}
+
+func call[T any](ctx context.Context, p *rpcPool, fn func(*ethclient.Client) (T, error)) (T, error) {
+	var out T
+	err := p.do(ctx, func(cl *ethclient.Client) error {
+		v, err := fn(cl)
+		if err == nil {
+			out = v
+		}
+		return err
+	})
+	return out, err
+}
+
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// -32005 is the de facto "limit exceeded" code used by hosted providers.
+		return rpcErr.ErrorCode() == -32005
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		return false
+	}
+	return true
+}
+
+func (p *rpcPool) ChainID(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*big.Int, error) { return cl.ChainID(ctx) })
+}
+
+func (p *rpcPool) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (uint64, error) { return cl.BlockNumber(ctx) })
+}
+
+func (p *rpcPool) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*types.Header, error) { return cl.HeaderByNumber(ctx, number) })
+}
+
+func (p *rpcPool) CodeAt(ctx context.Context, account common.Address, number *big.Int) ([]byte, error) {
+	return call(ctx, p, func(cl *ethclient.Client) ([]byte, error) { return cl.CodeAt(ctx, account, number) })
+}
+
+func (p *rpcPool) CallContract(ctx context.Context, msg ethereum.CallMsg, number *big.Int) ([]byte, error) {
+	return call(ctx, p, func(cl *ethclient.Client) ([]byte, error) { return cl.CallContract(ctx, msg, number) })
+}
+
+func (p *rpcPool) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return call(ctx, p, func(cl *ethclient.Client) ([]byte, error) { return cl.PendingCallContract(ctx, msg) })
+}
+
+func (p *rpcPool) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, p, func(cl *ethclient.Client) ([]types.Log, error) { return cl.FilterLogs(ctx, q) })
+}
+
+func (p *rpcPool) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*types.Receipt, error) { return cl.TransactionReceipt(ctx, hash) })
+}
+
+func (p *rpcPool) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	res, err := call(ctx, p, func(cl *ethclient.Client) (result, error) {
+		tx, pending, err := cl.TransactionByHash(ctx, hash)
+		return result{tx, pending}, err
+	})
+	return res.tx, res.pending, err
+}
+
+func (p *rpcPool) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (uint64, error) { return cl.EstimateGas(ctx, msg) })
+}
+
+func (p *rpcPool) BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*big.Int, error) { return cl.BalanceAt(ctx, account, block) })
+}
+
+func (p *rpcPool) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (uint64, error) { return cl.PendingNonceAt(ctx, account) })
+}
+
+func (p *rpcPool) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*big.Int, error) { return cl.SuggestGasTipCap(ctx) })
+}
+
+func (p *rpcPool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, p, func(cl *ethclient.Client) (*big.Int, error) { return cl.SuggestGasPrice(ctx) })
+}
+
+func (p *rpcPool) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return p.do(ctx, func(cl *ethclient.Client) error { return cl.SendTransaction(ctx, tx) })
+}
+
+// pinnedBlock asks each quorum member for its head and returns the lowest,
+// so every member can answer for the same block.
+func (p *rpcPool) pinnedBlock(ctx context.Context, n int) (*big.Int, error) {
+	heads, err := each(ctx, p, n, func(cl *ethclient.Client) (uint64, error) { return cl.BlockNumber(ctx) })
+	if err != nil {
+		return nil, err
+	}
+	lowest := heads[0]
+	for _, h := range heads[1:] {
+		if h < lowest {
+			lowest = h
+		}
+	}
+	return new(big.Int).SetUint64(lowest), nil
+}
+
+// quorumHeader returns the header at a block every quorum member has seen,
+// after checking that all members report the same hash for it.
+func (p *rpcPool) quorumHeader(ctx context.Context, n int) (*types.Header, error) {
+	block, err := p.pinnedBlock(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := each(ctx, p, n, func(cl *ethclient.Client) (*types.Header, error) { return cl.HeaderByNumber(ctx, block) })
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range headers[1:] {
+		if h.Hash() != headers[0].Hash() {
+			return nil, fmt.Errorf("%w: block %d hash %s vs %s", ErrQuorumMismatch, block, headers[0].Hash().Hex(), h.Hash().Hex())
+		}
+	}
+	return headers[0], nil
+}
+
+// quorumCall runs an eth_call on n endpoints at a common block and requires
+// byte-identical results.
+func (p *rpcPool) quorumCall(ctx context.Context, n int, msg ethereum.CallMsg) ([]byte, error) {
+	block, err := p.pinnedBlock(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	results, err := each(ctx, p, n, func(cl *ethclient.Client) ([]byte, error) { return cl.CallContract(ctx, msg, block) })
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results[1:] {
+		if string(res) != string(results[0]) {
+			return nil, fmt.Errorf("%w: eth_call at block %d", ErrQuorumMismatch, block)
+		}
+	}
+	return results[0], nil
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+func inprocClient(t *testing.T, chain *fakeChain) *ethclient.Client {
+	t.Helper()
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", chain); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	t.Cleanup(srv.Stop)
+	return ethclient.NewClient(rpc.DialInProc(srv))
+}
+
+func unavailableClient(t *testing.T) *ethclient.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	cl, err := ethclient.Dial(srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	return cl
+}
+
+func TestPoolFailsOverAndOpensBreaker(t *testing.T) {
+	healthy := &fakeChain{head: 42, fork: map[uint64]string{}}
+	pool := newPool(map[string]*ethclient.Client{
+		"a-down": unavailableClient(t),
+		"b-up":   inprocClient(t, healthy),
+	}, zap.NewNop())
+	pool.threshold = 2
+	pool.cooldown = time.Hour
+	// Make the failing endpoint look fastest so it is tried first.
+	pool.endpoints[0].latency = time.Microsecond
+	pool.endpoints[1].latency = time.Second
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		head, err := pool.BlockNumber(ctx)
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if head != 42 {
+			t.Fatalf("call %d: got head %d want 42", i, head)
+		}
+	}
+
+	health := pool.Health()
+	if health[0].Breaker != "open" {
+		t.Fatalf("expected breaker open on failing endpoint, got %s", health[0].Breaker)
+	}
+	if health[1].Breaker != "closed" {
+		t.Fatalf("expected healthy endpoint closed, got %s", health[1].Breaker)
+	}
+	if ordered := pool.ordered(); len(ordered) != 1 || ordered[0].name != "b-up" {
+		t.Fatalf("expected only the healthy endpoint in rotation")
+	}
+}
+
+func TestBreakerHalfOpenAfterCooldown(t *testing.T) {
+	ep := &endpoint{name: "x"}
+	for i := 0; i < 3; i++ {
+		ep.record(time.Millisecond, true, 3, time.Millisecond)
+	}
+	if ep.allow(time.Now()) {
+		t.Fatalf("expected open breaker to reject calls")
+	}
+	if !ep.allow(time.Now().Add(time.Second)) {
+		t.Fatalf("expected a trial call after cooldown")
+	}
+	if state, _ := ep.record(time.Millisecond, true, 3, time.Hour); state != breakerOpen {
+		t.Fatalf("failed trial should reopen the breaker, got %s", state)
+	}
+	ep.openUntil = time.Time{}
+	ep.allow(time.Now())
+	if state, _ := ep.record(time.Millisecond, false, 3, time.Hour); state != breakerClosed {
+		t.Fatalf("successful trial should close the breaker, got %s", state)
+	}
+}
+
+func TestQuorumHeaderMismatch(t *testing.T) {
+	a := &fakeChain{head: 10, fork: map[uint64]string{}}
+	b := &fakeChain{head: 12, fork: map[uint64]string{}}
+	pool := newPool(map[string]*ethclient.Client{
+		"a": inprocClient(t, a),
+		"b": inprocClient(t, b),
+	}, zap.NewNop())
+	ctx := context.Background()
+
+	header, err := pool.quorumHeader(ctx, 2)
+	if err != nil {
+		t.Fatalf("quorum header: %v", err)
+	}
+	if header.Number.Uint64() != 10 {
+		t.Fatalf("expected header pinned to lowest head 10, got %d", header.Number.Uint64())
+	}
+
+	b.mu.Lock()
+	b.fork[10] = "other"
+	b.mu.Unlock()
+	if _, err := pool.quorumHeader(ctx, 2); !errors.Is(err, ErrQuorumMismatch) {
+		t.Fatalf("expected quorum mismatch, got %v", err)
+	}
+
+	if _, err := pool.quorumHeader(ctx, 3); err == nil {
+		t.Fatalf("expected error when quorum exceeds endpoints")
+	}
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"base-treasury-guard/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/term"
+)
+
+var ErrNoSigner = errors.New("no signer configured for this role")
+
+// Signer signs transactions for one account. Implementations keep the key
+// in process or delegate to an external signer.
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// KeySigner signs with a private key held in memory, decrypted or parsed
+// once at startup.
+type KeySigner struct {
+	key  *ecdsa.PrivateKey
+	addr common.Address
+}
+
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key, addr: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore file.
+func NewKeystoreSigner(path, password string) (*KeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(key.PrivateKey), nil
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.addr
+}
+
+func (s *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// addressSigner stands in for an account whose key is not available. A dry
+// run only needs the address to simulate and estimate as that account.
+type addressSigner common.Address
+
+func (s addressSigner) Address() common.Address {
+	return common.Address(s)
+}
+
+func (s addressSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, fmt.Errorf("%s: %w", common.Address(s).Hex(), ErrNoSigner)
+}
+
+type signerConfig struct {
+	key          config.Secret
+	keystore     string
+	passwordFile string
+	url          string
+	address      string
+	method       string
+	dryRun       bool
+}
+
+// loadSigner returns the signer for one role. A remote signer URL takes
+// precedence, then a keystore file, then a raw hex key. In a dry run the
+// role's address alone is enough; otherwise with none the role has no
+// signer. Errors never include the key or passphrase.
+func loadSigner(role string, cfg signerConfig) (Signer, error) {
+	switch {
+	case cfg.url != "":
+		if !common.IsHexAddress(cfg.address) {
+			return nil, fmt.Errorf("%s remote signer needs a valid account address", role)
+		}
+		s, err := DialRemoteSigner(cfg.url, common.HexToAddress(cfg.address), cfg.method)
+		if err != nil {
+			return nil, fmt.Errorf("%s remote signer: %w", role, err)
+		}
+		return s, nil
+	case cfg.keystore != "":
+		password, err := readPassword(role, cfg.keystore, cfg.passwordFile)
+		if err != nil {
+			return nil, err
+		}
+		s, err := NewKeystoreSigner(cfg.keystore, password)
+		if err != nil {
+			return nil, fmt.Errorf("%s keystore %s: %w", role, cfg.keystore, err)
+		}
+		return s, nil
+	case cfg.key != "":
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(cfg.key.Reveal(), "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("%s key is not a valid hex private key", role)
+		}
+		return NewKeySigner(key), nil
+	case cfg.dryRun && common.IsHexAddress(cfg.address):
+		return addressSigner(common.HexToAddress(cfg.address)), nil
+	}
+	return nil, nil
+}
+
+// readPassword reads the keystore passphrase from passwordFile, or prompts
+// on the terminal without echo when no file is configured.
+func readPassword(role, keystorePath, passwordFile string) (string, error) {
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("%s password file: %w", role, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("%s keystore %s needs a password file when stdin is not a terminal", role, keystorePath)
+	}
+	fmt.Fprintf(os.Stderr, "Passphrase for %s keystore %s: ", role, keystorePath)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("%s passphrase: %w", role, err)
+	}
+	return string(password), nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"base-treasury-guard/internal/config"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestLoadSignerFromKeystore(t *testing.T) {
+	dir := t.TempDir()
+	account, err := keystore.StoreKey(filepath.Join(dir, "keys"), "hunter2", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("store key: %v", err)
+	}
+	path, want := account.URL.Path, account.Address
+	good := filepath.Join(dir, "good.txt")
+	bad := filepath.Join(dir, "bad.txt")
+	_ = os.WriteFile(good, []byte("hunter2\n"), 0o600)
+	_ = os.WriteFile(bad, []byte("wrong\n"), 0o600)
+
+	s, err := loadSigner("guardian", signerConfig{keystore: path, passwordFile: good})
+	if err != nil {
+		t.Fatalf("load keystore: %v", err)
+	}
+	if s.Address() != want {
+		t.Fatalf("got address %s, want %s", s.Address().Hex(), want.Hex())
+	}
+	if _, err := loadSigner("guardian", signerConfig{keystore: path, passwordFile: bad}); err == nil {
+		t.Fatalf("expected wrong passphrase to fail")
+	}
+
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	raw := config.Secret(hexutil.Encode(crypto.FromECDSA(priv)))
+	if s, err := loadSigner("executor", signerConfig{key: raw}); err != nil || s.Address() != crypto.PubkeyToAddress(priv.PublicKey) {
+		t.Fatalf("raw key: got %v, %v", s, err)
+	}
+	if s, err := loadSigner("executor", signerConfig{}); err != nil || s != nil {
+		t.Fatalf("expected no signer without a key, got %v, %v", s, err)
+	}
+	_, err = loadSigner("executor", signerConfig{key: "0xnot-a-key"})
+	if err == nil || strings.Contains(err.Error(), "not-a-key") {
+		t.Fatalf("expected an error that hides the key, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SendResult is the outcome of Approve and ExecuteBatch. When Skipped is set
+// the pre-flight simulation reverted and nothing was signed or sent; Reason
+// is the decoded revert reason and Cause the matching sentinel error. In
+// dry-run mode DryRun is set instead of Hash, with the gas limit and worst
+// case fee the transaction would have been sent with.
+type SendResult struct {
+	Hash    common.Hash
+	Skipped bool
+	Reason  string
+	Cause   error
+
+	DryRun bool
+	Gas    uint64
+	Fee    *big.Int
+}
+
+func skipped(revert *RevertError) SendResult {
+	return SendResult{Skipped: true, Reason: revert.Reason, Cause: revert}
+}
+
+// simulate runs the call with eth_call against the pending block as the
+// signer would send it. It returns the decoded revert when the call would
+// revert; an error means the simulation itself failed.
+func (c *EthClient) simulate(ctx context.Context, from common.Address, data []byte) (*RevertError, error) {
+	_, err := c.rpc.PendingCallContract(ctx, ethereum.CallMsg{From: from, To: &c.contract, Data: data})
+	if err == nil {
+		return nil, nil
+	}
+	if revert, ok := revertFromError(err); ok {
+		return revert, nil
+	}
+	return nil, err
+}
+
+// revertFromError extracts the revert from an eth_call or eth_estimateGas
+// error, preferring the raw revert data when the node returns it.
+func revertFromError(err error) (*RevertError, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if raw, decodeErr := hexutil.Decode(hexData); decodeErr == nil && len(raw) > 0 {
+				return decodeRevert(raw), true
+			}
+		}
+	}
+	msg := err.Error()
+	if i := strings.Index(strings.ToLower(msg), "execution reverted"); i >= 0 {
+		reason := strings.TrimSpace(strings.TrimPrefix(msg[i+len("execution reverted"):], ":"))
+		return revertFromReason(reason, nil), true
+	}
+	return nil, false
+}
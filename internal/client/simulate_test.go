@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+type revertError struct {
+	data string
+}
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorCode() int         { return 3 }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+// revertingEth answers eth_call with a fixed revert payload and records the
+// block tag it was asked to simulate against.
+type revertingEth struct {
+	data  []byte
+	block string
+}
+
+func (r *revertingEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	r.block = block
+	if r.data == nil {
+		return hexutil.Bytes{}, nil
+	}
+	return nil, revertError{data: hexutil.Encode(r.data)}
+}
+
+func TestSimulateDecodesRevertReason(t *testing.T) {
+	errorString := crypto.Keccak256([]byte("Error(string)"))[:4]
+	reasonData := append(append([]byte{}, errorString...), common.LeftPadBytes([]byte{0x20}, 32)...)
+	reasonData = append(reasonData, common.LeftPadBytes([]byte{16}, 32)...)
+	reasonData = append(reasonData, common.RightPadBytes([]byte("ALREADY_APPROVED"), 32)...)
+	paused := crypto.Keccak256([]byte("EnforcedPause()"))[:4]
+	unknown := crypto.Keccak256([]byte("SomethingElse()"))[:4]
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	cases := []struct {
+		name     string
+		data     []byte
+		reverted bool
+		reason   string
+		cause    error
+	}{
+		{"errorString", reasonData, true, "ALREADY_APPROVED", ErrAlreadyApproved},
+		{"customError", paused, true, "EnforcedPause", ErrPaused},
+		{"unknownCustomError", unknown, true, hexutil.Encode(unknown), ErrReverted},
+		{"succeeds", nil, false, "", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &revertingEth{data: tc.data}
+			srv := rpc.NewServer()
+			if err := srv.RegisterName("eth", svc); err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			defer srv.Stop()
+			c := &EthClient{
+				rpc: newPool(map[string]*ethclient.Client{"inproc": ethclient.NewClient(rpc.DialInProc(srv))}, zap.NewNop()),
+				log: zap.NewNop(),
+			}
+
+			revert, err := c.simulate(context.Background(), from, []byte{0x01})
+			if err != nil {
+				t.Fatalf("simulate: %v", err)
+			}
+			if (revert != nil) != tc.reverted {
+				t.Fatalf("got revert %v, want reverted=%v", revert, tc.reverted)
+			}
+			if revert != nil {
+				if revert.Reason != tc.reason || !errors.Is(revert, tc.cause) {
+					t.Fatalf("got reason %q (%v), want %q (%v)", revert.Reason, revert.Err, tc.reason, tc.cause)
+				}
+			}
+			if svc.block != "pending" {
+				t.Fatalf("expected simulation against pending state, got %q", svc.block)
+			}
+		})
+	}
+}
+
+// dryRunEth answers everything send needs before broadcasting and counts
+// raw transactions it is handed.
+type dryRunEth struct {
+	revertingEth
+	sent int
+}
+
+func (d *dryRunEth) EstimateGas(args map[string]interface{}) hexutil.Uint64 { return 100000 }
+func (d *dryRunEth) MaxPriorityFeePerGas() *hexutil.Big                     { return (*hexutil.Big)(big.NewInt(1)) }
+func (d *dryRunEth) GasPrice() *hexutil.Big                                 { return (*hexutil.Big)(big.NewInt(10)) }
+func (d *dryRunEth) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return nil
+}
+
+func (d *dryRunEth) GetBlockByNumber(number string, full bool) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), GasLimit: 30_000_000}, nil
+}
+
+func (d *dryRunEth) SendRawTransaction(raw hexutil.Bytes) common.Hash {
+	d.sent++
+	return common.Hash{}
+}
+
+func TestDryRunNeverBroadcasts(t *testing.T) {
+	svc := &dryRunEth{}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", svc); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	defer srv.Stop()
+	c := &EthClient{
+		rpc:           newPool(map[string]*ethclient.Client{"inproc": ethclient.NewClient(rpc.DialInProc(srv))}, zap.NewNop()),
+		log:           zap.NewNop(),
+		chainID:       big.NewInt(8453),
+		gasMultiplier: 1.25,
+		dryRun:        true,
+	}
+
+	res, err := c.send(context.Background(), "executor", addressSigner(common.HexToAddress("0x01")), []byte{0x01}, 0, 0, false)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if !res.DryRun || res.Gas != 125000 || res.Fee == nil || res.Fee.Int64() != 1250000 {
+		t.Fatalf("unexpected dry run result %+v", res)
+	}
+	if svc.sent != 0 || res.Hash != (common.Hash{}) {
+		t.Fatalf("dry run broadcast %d transactions", svc.sent)
+	}
+}
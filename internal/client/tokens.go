@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc20MetadataABIJSON = `[
+  {
+    "type": "function",
+    "name": "decimals",
+    "stateMutability": "view",
+    "inputs": [],
+    "outputs": [{ "name": "", "type": "uint8" }]
+  },
+  {
+    "type": "function",
+    "name": "symbol",
+    "stateMutability": "view",
+    "inputs": [],
+    "outputs": [{ "name": "", "type": "string" }]
+  }
+]`
+
+// TokenInfo is the display metadata of a request token. The zero address is
+// native ETH.
+type TokenInfo struct {
+	Address  common.Address
+	Symbol   string
+	Decimals uint8
+}
+
+var nativeTokenInfo = TokenInfo{Symbol: "ETH", Decimals: 18}
+
+// Format renders amount in whole token units followed by the symbol.
+func (t TokenInfo) Format(amount *big.Int) string {
+	return FormatUnits(amount, t.Decimals) + " " + t.Symbol
+}
+
+// TokenInfo returns the decimals and symbol of token. Results are cached for
+// the life of the client since neither can change for a deployed ERC20.
+func (c *EthClient) TokenInfo(ctx context.Context, token common.Address) (TokenInfo, error) {
+	if token == (common.Address{}) {
+		return nativeTokenInfo, nil
+	}
+	c.mu.Lock()
+	info, ok := c.tokens[token]
+	c.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc20MetadataABIJSON))
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	info = TokenInfo{Address: token}
+	res, err := c.callToken(ctx, parsed, token, "decimals")
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	out, err := parsed.Unpack("decimals", res)
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("token %s decimals: %w", token.Hex(), err)
+	}
+	decimals, ok := out[0].(uint8)
+	if !ok {
+		return TokenInfo{}, fmt.Errorf("invalid decimals type %T", out[0])
+	}
+	info.Decimals = decimals
+
+	res, err = c.callToken(ctx, parsed, token, "symbol")
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	info.Symbol = decodeSymbol(parsed, res)
+	if info.Symbol == "" {
+		info.Symbol = token.Hex()
+	}
+
+	c.mu.Lock()
+	if c.tokens == nil {
+		c.tokens = make(map[common.Address]TokenInfo)
+	}
+	c.tokens[token] = info
+	c.mu.Unlock()
+	return info, nil
+}
+
+func (c *EthClient) callToken(ctx context.Context, parsed abi.ABI, token common.Address, method string) ([]byte, error) {
+	data, err := parsed.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.rpc.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("token %s %s: %w", token.Hex(), method, err)
+	}
+	return res, nil
+}
+
+// decodeSymbol accepts both the standard string return and the bytes32 used
+// by some early tokens.
+func decodeSymbol(parsed abi.ABI, res []byte) string {
+	if out, err := parsed.Unpack("symbol", res); err == nil {
+		if s, ok := out[0].(string); ok {
+			return strings.TrimSpace(s)
+		}
+	}
+	if len(res) == 32 {
+		return strings.TrimSpace(strings.TrimRight(string(res), "\x00"))
+	}
+	return ""
+}
+
+// FormatUnits renders amount with the given number of decimals, trimming
+// trailing zeros: 1500000 with 6 decimals is "1.5".
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "0"
+	}
+	neg := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	d := int(decimals)
+	if len(digits) <= d {
+		digits = strings.Repeat("0", d-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-d], strings.TrimRight(digits[len(digits)-d:], "0")
+	out := whole
+	if frac != "" {
+		out += "." + frac
+	}
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
+
+var ErrTooManyDecimals = errors.New("amount has more decimals than the token")
+
+// ParseUnits converts a decimal string such as "5000.25" into base units.
+func ParseUnits(value string, decimals uint8) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	whole, frac, _ := strings.Cut(value, ".")
+	if whole == "" && frac == "" {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("%w: %q allows %d", ErrTooManyDecimals, value, decimals)
+	}
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("invalid amount %q", value)
+		}
+	}
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFormatAndParseUnits(t *testing.T) {
+	cases := []struct {
+		raw      string
+		decimals uint8
+		human    string
+	}{
+		{"5000000000", 6, "5000"},
+		{"1500000", 6, "1.5"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 6, "0"},
+		{"42", 0, "42"},
+	}
+	for _, tc := range cases {
+		raw, _ := new(big.Int).SetString(tc.raw, 10)
+		if got := FormatUnits(raw, tc.decimals); got != tc.human {
+			t.Fatalf("format %s/%d: got %q want %q", tc.raw, tc.decimals, got, tc.human)
+		}
+		parsed, err := ParseUnits(tc.human, tc.decimals)
+		if err != nil || parsed.Cmp(raw) != 0 {
+			t.Fatalf("parse %q/%d: got %v, %v want %s", tc.human, tc.decimals, parsed, err, tc.raw)
+		}
+	}
+
+	if _, err := ParseUnits("1.0000001", 6); !errors.Is(err, ErrTooManyDecimals) {
+		t.Fatalf("expected too many decimals, got %v", err)
+	}
+	for _, bad := range []string{"", ".", "1e6", "-5", "1.2.3"} {
+		if _, err := ParseUnits(bad, 6); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+	if got := (TokenInfo{Symbol: "USDC", Decimals: 6}).Format(big.NewInt(2500000)); got != "2.5 USDC" {
+		t.Fatalf("unexpected formatted amount %q", got)
+	}
+}
@@ -21,6 +21,120 @@ const treasuryGuardABIJSON = `[
     "name": "RequestCreated",
     "type": "event"
   },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "uint256", "name": "id", "type": "uint256"},
+      {"indexed": true, "internalType": "address", "name": "guardian", "type": "address"},
+      {"indexed": false, "internalType": "uint256", "name": "approvalsCount", "type": "uint256"}
+    ],
+    "name": "RequestApproved",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "uint256", "name": "id", "type": "uint256"},
+      {"indexed": true, "internalType": "address", "name": "cancelledBy", "type": "address"}
+    ],
+    "name": "RequestCancelled",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "uint256", "name": "id", "type": "uint256"},
+      {"indexed": true, "internalType": "address", "name": "expiredBy", "type": "address"}
+    ],
+    "name": "RequestExpired",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "uint256", "name": "id", "type": "uint256"},
+      {"indexed": true, "internalType": "address", "name": "executor", "type": "address"},
+      {"indexed": false, "internalType": "uint256", "name": "gasUsed", "type": "uint256"}
+    ],
+    "name": "RequestExecuted",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": false, "internalType": "uint256[]", "name": "idsProcessed", "type": "uint256[]"},
+      {"indexed": true, "internalType": "address", "name": "executor", "type": "address"},
+      {"indexed": false, "internalType": "uint256", "name": "gasUsed", "type": "uint256"}
+    ],
+    "name": "BatchExecuted",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": false, "internalType": "uint64", "name": "minDelay", "type": "uint64"},
+      {"indexed": false, "internalType": "uint64", "name": "maxPendingDuration", "type": "uint64"},
+      {"indexed": false, "internalType": "uint256", "name": "maxPerTx", "type": "uint256"},
+      {"indexed": false, "internalType": "uint8", "name": "maxApprovalsNeeded", "type": "uint8"}
+    ],
+    "name": "ParamsUpdated",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "address", "name": "token", "type": "address"},
+      {"indexed": false, "internalType": "bool", "name": "allowed", "type": "bool"}
+    ],
+    "name": "TokenAllowlistUpdated",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": false, "internalType": "address", "name": "account", "type": "address"}
+    ],
+    "name": "Paused",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": false, "internalType": "address", "name": "account", "type": "address"}
+    ],
+    "name": "Unpaused",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "bytes32", "name": "role", "type": "bytes32"},
+      {"indexed": true, "internalType": "bytes32", "name": "previousAdminRole", "type": "bytes32"},
+      {"indexed": true, "internalType": "bytes32", "name": "newAdminRole", "type": "bytes32"}
+    ],
+    "name": "RoleAdminChanged",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "bytes32", "name": "role", "type": "bytes32"},
+      {"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+      {"indexed": true, "internalType": "address", "name": "sender", "type": "address"}
+    ],
+    "name": "RoleGranted",
+    "type": "event"
+  },
+  {
+    "anonymous": false,
+    "inputs": [
+      {"indexed": true, "internalType": "bytes32", "name": "role", "type": "bytes32"},
+      {"indexed": true, "internalType": "address", "name": "account", "type": "address"},
+      {"indexed": true, "internalType": "address", "name": "sender", "type": "address"}
+    ],
+    "name": "RoleRevoked",
+    "type": "event"
+  },
   {
     "inputs": [
       {"internalType": "uint256", "name": "id", "type": "uint256"}
@@ -40,6 +154,30 @@ const treasuryGuardABIJSON = `[
     "stateMutability": "nonpayable",
     "type": "function"
   },
+  {
+    "inputs": [
+      {"internalType": "uint256", "name": "", "type": "uint256"},
+      {"internalType": "address", "name": "", "type": "address"}
+    ],
+    "name": "approvalsByGuardian",
+    "outputs": [
+      {"internalType": "bool", "name": "", "type": "bool"}
+    ],
+    "stateMutability": "view",
+    "type": "function"
+  },
+  {
+    "inputs": [
+      {"internalType": "bytes32", "name": "role", "type": "bytes32"},
+      {"internalType": "address", "name": "account", "type": "address"}
+    ],
+    "name": "hasRole",
+    "outputs": [
+      {"internalType": "bool", "name": "", "type": "bool"}
+    ],
+    "stateMutability": "view",
+    "type": "function"
+  },
   {
     "inputs": [
       {"internalType": "uint256", "name": "", "type": "uint256"}
@@ -8,17 +8,17 @@ import (
 )
 
 type RequestState struct {
-	ID              uint64
-	Token           common.Address
-	To              common.Address
-	Amount          *big.Int
-	CreatedBy       common.Address
-	Approvals       uint64
-	ApprovalsNeeded uint64
-	CreatedAt       uint64
-	EarliestExec    uint64
-	ExpiresAt       uint64
-	Status          uint8
+	ID              uint64         `json:"id"`
+	Token           common.Address `json:"token"`
+	To              common.Address `json:"to"`
+	Amount          *big.Int       `json:"amount"`
+	CreatedBy       common.Address `json:"createdBy"`
+	Approvals       uint64         `json:"approvals"`
+	ApprovalsNeeded uint64         `json:"approvalsNeeded"`
+	CreatedAt       uint64         `json:"createdAt"`
+	EarliestExec    uint64         `json:"earliestExec"`
+	ExpiresAt       uint64         `json:"expiresAt"`
+	Status          uint8          `json:"status"`
 }
 
 func unpackRequest(values []interface{}) (RequestState, error) {
@@ -1,124 +1,355 @@
 package client
 
 import (
-	"context"
 	"errors"
+	"fmt"
 	"math/big"
-	"time"
+	"sort"
 
-	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+type EventMeta struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	LogIndex    uint
+	Removed     bool
+}
+
+func (m EventMeta) Metadata() EventMeta {
+	return m
+}
+
+// Event is implemented by every decoded TreasuryGuard log.
+type Event interface {
+	EventName() string
+	Metadata() EventMeta
+}
+
 type RequestCreatedEvent struct {
-	ID     *big.Int
-	Token  common.Address
-	To     common.Address
-	Amount *big.Int
-}
-
-func (c *EthClient) SubscribeRequestCreated(ctx context.Context) (<-chan RequestCreatedEvent, <-chan error) {
-	out := make(chan RequestCreatedEvent)
-	errCh := make(chan error, 1)
-
-	go func() {
-		defer close(out)
-		defer close(errCh)
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
-
-			evt, ok := c.abi.Events["RequestCreated"]
-			if !ok {
-				sendErr(errCh, errors.New("missing RequestCreated ABI"))
-				return
-			}
-
-			if err := c.dialWS(); err != nil {
-				sendErr(errCh, err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
-
-			query := ethereum.FilterQuery{
-				Addresses: []common.Address{c.contract},
-				Topics:    [][]common.Hash{{evt.ID}},
-			}
-
-			logs := make(chan types.Log)
-			sub, err := c.ws.SubscribeFilterLogs(ctx, query, logs)
-			if err != nil {
-				sendErr(errCh, err)
-				time.Sleep(5 * time.Second)
-				continue
-			}
-
-			for {
-				select {
-				case <-ctx.Done():
-					sub.Unsubscribe()
-					return
-				case err := <-sub.Err():
-					if err != nil {
-						sendErr(errCh, err)
-					}
-					sub.Unsubscribe()
-					time.Sleep(5 * time.Second)
-					goto resubscribe
-				case lg := <-logs:
-					if lg.Removed {
-						continue
-					}
-					evt, err := c.parseRequestCreated(lg)
-					if err != nil {
-						sendErr(errCh, err)
-						continue
-					}
-					out <- evt
-				}
-			}
-		resubscribe:
-			continue
-		}
-	}()
+	EventMeta
+	ID              *big.Int
+	Token           common.Address
+	To              common.Address
+	Amount          *big.Int
+	ApprovalsNeeded uint64
+	CreatedBy       common.Address
+	EarliestExec    uint64
+}
+
+type RequestApprovedEvent struct {
+	EventMeta
+	ID             *big.Int
+	Guardian       common.Address
+	ApprovalsCount uint64
+}
+
+type RequestCancelledEvent struct {
+	EventMeta
+	ID          *big.Int
+	CancelledBy common.Address
+}
+
+type RequestExpiredEvent struct {
+	EventMeta
+	ID        *big.Int
+	ExpiredBy common.Address
+}
+
+type RequestExecutedEvent struct {
+	EventMeta
+	ID       *big.Int
+	Executor common.Address
+	GasUsed  uint64
+}
+
+type BatchExecutedEvent struct {
+	EventMeta
+	IDsProcessed []*big.Int
+	Executor     common.Address
+	GasUsed      uint64
+}
+
+type ParamsUpdatedEvent struct {
+	EventMeta
+	MinDelay           uint64
+	MaxPendingDuration uint64
+	MaxPerTx           *big.Int
+	MaxApprovalsNeeded uint8
+}
+
+type TokenAllowlistUpdatedEvent struct {
+	EventMeta
+	Token   common.Address
+	Allowed bool
+}
 
-	return out, errCh
+type PausedEvent struct {
+	EventMeta
+	Account common.Address
 }
 
-func (c *EthClient) parseRequestCreated(lg types.Log) (RequestCreatedEvent, error) {
-	if len(lg.Topics) < 4 {
-		return RequestCreatedEvent{}, errors.New("invalid RequestCreated topics")
+type UnpausedEvent struct {
+	EventMeta
+	Account common.Address
+}
+
+type RoleGrantedEvent struct {
+	EventMeta
+	Role    common.Hash
+	Account common.Address
+	Sender  common.Address
+}
+
+type RoleRevokedEvent struct {
+	EventMeta
+	Role    common.Hash
+	Account common.Address
+	Sender  common.Address
+}
+
+type RoleAdminChangedEvent struct {
+	EventMeta
+	Role              common.Hash
+	PreviousAdminRole common.Hash
+	NewAdminRole      common.Hash
+}
+
+func (RequestCreatedEvent) EventName() string        { return "RequestCreated" }
+func (RequestApprovedEvent) EventName() string       { return "RequestApproved" }
+func (RequestCancelledEvent) EventName() string      { return "RequestCancelled" }
+func (RequestExpiredEvent) EventName() string        { return "RequestExpired" }
+func (RequestExecutedEvent) EventName() string       { return "RequestExecuted" }
+func (BatchExecutedEvent) EventName() string         { return "BatchExecuted" }
+func (ParamsUpdatedEvent) EventName() string         { return "ParamsUpdated" }
+func (TokenAllowlistUpdatedEvent) EventName() string { return "TokenAllowlistUpdated" }
+func (PausedEvent) EventName() string                { return "Paused" }
+func (UnpausedEvent) EventName() string              { return "Unpaused" }
+func (RoleGrantedEvent) EventName() string           { return "RoleGranted" }
+func (RoleRevokedEvent) EventName() string           { return "RoleRevoked" }
+func (RoleAdminChangedEvent) EventName() string      { return "RoleAdminChanged" }
+
+var (
+	DefaultAdminRole = common.Hash{}
+	TreasurerRole    = crypto.Keccak256Hash([]byte("TREASURER_ROLE"))
+	GuardianRole     = crypto.Keccak256Hash([]byte("GUARDIAN_ROLE"))
+	ExecutorRole     = crypto.Keccak256Hash([]byte("EXECUTOR_ROLE"))
+)
+
+func RoleName(role common.Hash) string {
+	switch role {
+	case DefaultAdminRole:
+		return "DEFAULT_ADMIN_ROLE"
+	case TreasurerRole:
+		return "TREASURER_ROLE"
+	case GuardianRole:
+		return "GUARDIAN_ROLE"
+	case ExecutorRole:
+		return "EXECUTOR_ROLE"
+	default:
+		return role.Hex()
 	}
+}
+
+func (c *EthClient) eventTopics() []common.Hash {
+	topics := make([]common.Hash, 0, len(c.abi.Events))
+	for _, evt := range c.abi.Events {
+		topics = append(topics, evt.ID)
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Hex() < topics[j].Hex() })
+	return topics
+}
 
-	decoded, err := c.abi.Unpack("RequestCreated", lg.Data)
+// DecodeEvent turns a TreasuryGuard log into its typed event.
+func (c *EthClient) DecodeEvent(lg types.Log) (Event, error) {
+	return decodeEvent(c.abi, lg)
+}
+
+func decodeEvent(parsed abi.ABI, lg types.Log) (Event, error) {
+	if len(lg.Topics) == 0 {
+		return nil, errors.New("log has no topics")
+	}
+	evt, err := parsed.EventByID(lg.Topics[0])
 	if err != nil {
-		return RequestCreatedEvent{}, err
+		return nil, fmt.Errorf("unknown event topic %s", lg.Topics[0].Hex())
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range evt.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(lg.Topics) != len(indexed)+1 {
+		return nil, fmt.Errorf("invalid %s topics", evt.Name)
+	}
+
+	fields := make(map[string]interface{})
+	if err := abi.ParseTopicsIntoMap(fields, indexed, lg.Topics[1:]); err != nil {
+		return nil, err
+	}
+	if len(lg.Data) > 0 {
+		if err := parsed.UnpackIntoMap(fields, evt.Name, lg.Data); err != nil {
+			return nil, err
+		}
+	}
+	f := eventFields{name: evt.Name, values: fields}
+	meta := EventMeta{
+		BlockNumber: lg.BlockNumber,
+		BlockHash:   lg.BlockHash,
+		TxHash:      lg.TxHash,
+		LogIndex:    lg.Index,
+		Removed:     lg.Removed,
+	}
+
+	var out Event
+	switch evt.Name {
+	case "RequestCreated":
+		out = RequestCreatedEvent{
+			EventMeta:       meta,
+			ID:              f.bigInt("id"),
+			Token:           f.address("token"),
+			To:              f.address("to"),
+			Amount:          f.bigInt("amount"),
+			ApprovalsNeeded: f.uint64("approvalsNeeded"),
+			CreatedBy:       f.address("createdBy"),
+			EarliestExec:    f.uint64("earliestExec"),
+		}
+	case "RequestApproved":
+		out = RequestApprovedEvent{
+			EventMeta:      meta,
+			ID:             f.bigInt("id"),
+			Guardian:       f.address("guardian"),
+			ApprovalsCount: f.uint64("approvalsCount"),
+		}
+	case "RequestCancelled":
+		out = RequestCancelledEvent{EventMeta: meta, ID: f.bigInt("id"), CancelledBy: f.address("cancelledBy")}
+	case "RequestExpired":
+		out = RequestExpiredEvent{EventMeta: meta, ID: f.bigInt("id"), ExpiredBy: f.address("expiredBy")}
+	case "RequestExecuted":
+		out = RequestExecutedEvent{
+			EventMeta: meta,
+			ID:        f.bigInt("id"),
+			Executor:  f.address("executor"),
+			GasUsed:   f.uint64("gasUsed"),
+		}
+	case "BatchExecuted":
+		out = BatchExecutedEvent{
+			EventMeta:    meta,
+			IDsProcessed: f.bigInts("idsProcessed"),
+			Executor:     f.address("executor"),
+			GasUsed:      f.uint64("gasUsed"),
+		}
+	case "ParamsUpdated":
+		out = ParamsUpdatedEvent{
+			EventMeta:          meta,
+			MinDelay:           f.uint64("minDelay"),
+			MaxPendingDuration: f.uint64("maxPendingDuration"),
+			MaxPerTx:           f.bigInt("maxPerTx"),
+			MaxApprovalsNeeded: uint8(f.uint64("maxApprovalsNeeded")),
+		}
+	case "TokenAllowlistUpdated":
+		out = TokenAllowlistUpdatedEvent{EventMeta: meta, Token: f.address("token"), Allowed: f.bool("allowed")}
+	case "Paused":
+		out = PausedEvent{EventMeta: meta, Account: f.address("account")}
+	case "Unpaused":
+		out = UnpausedEvent{EventMeta: meta, Account: f.address("account")}
+	case "RoleGranted":
+		out = RoleGrantedEvent{
+			EventMeta: meta,
+			Role:      f.hash("role"),
+			Account:   f.address("account"),
+			Sender:    f.address("sender"),
+		}
+	case "RoleRevoked":
+		out = RoleRevokedEvent{
+			EventMeta: meta,
+			Role:      f.hash("role"),
+			Account:   f.address("account"),
+			Sender:    f.address("sender"),
+		}
+	case "RoleAdminChanged":
+		out = RoleAdminChangedEvent{
+			EventMeta:         meta,
+			Role:              f.hash("role"),
+			PreviousAdminRole: f.hash("previousAdminRole"),
+			NewAdminRole:      f.hash("newAdminRole"),
+		}
+	default:
+		return nil, fmt.Errorf("no decoder for %s", evt.Name)
+	}
+	if f.err != nil {
+		return nil, f.err
 	}
-	amount, ok := decoded[0].(*big.Int)
+	return out, nil
+}
+
+// eventFields reads typed values out of an unpacked log and keeps the first
+// type mismatch so decoders can check once at the end.
+type eventFields struct {
+	name   string
+	values map[string]interface{}
+	err    error
+}
+
+func (f *eventFields) fail(key string) {
+	if f.err == nil {
+		f.err = fmt.Errorf("invalid %s %s type", f.name, key)
+	}
+}
+
+func (f *eventFields) bigInt(key string) *big.Int {
+	v, ok := f.values[key].(*big.Int)
 	if !ok {
-		return RequestCreatedEvent{}, errors.New("invalid amount type")
+		f.fail(key)
+		return new(big.Int)
 	}
+	return v
+}
 
-	id := new(big.Int).SetBytes(lg.Topics[1].Bytes())
-	token := common.BytesToAddress(lg.Topics[2].Bytes())
-	to := common.BytesToAddress(lg.Topics[3].Bytes())
+func (f *eventFields) bigInts(key string) []*big.Int {
+	v, ok := f.values[key].([]*big.Int)
+	if !ok {
+		f.fail(key)
+	}
+	return v
+}
 
-	return RequestCreatedEvent{
-		ID:     id,
-		Token:  token,
-		To:     to,
-		Amount: amount,
-	}, nil
+func (f *eventFields) uint64(key string) uint64 {
+	if v, ok := f.values[key].(uint8); ok {
+		return uint64(v)
+	}
+	v, ok := asUint64(f.values[key])
+	if !ok {
+		f.fail(key)
+	}
+	return v
 }
 
-func sendErr(ch chan error, err error) {
-	select {
-	case ch <- err:
-	default:
+func (f *eventFields) address(key string) common.Address {
+	v, ok := f.values[key].(common.Address)
+	if !ok {
+		f.fail(key)
+	}
+	return v
+}
+
+func (f *eventFields) hash(key string) common.Hash {
+	v, ok := f.values[key].([32]byte)
+	if !ok {
+		f.fail(key)
+	}
+	return common.Hash(v)
+}
+
+func (f *eventFields) bool(key string) bool {
+	v, ok := f.values[key].(bool)
+	if !ok {
+		f.fail(key)
 	}
+	return v
 }
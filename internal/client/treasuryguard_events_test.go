@@ -0,0 +1,85 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeEvents(t *testing.T) {
+	parsed, err := ParseTreasuryGuardABI()
+	if err != nil {
+		t.Fatalf("parse abi: %v", err)
+	}
+	token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	creator := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	created := parsed.Events["RequestCreated"]
+	data, err := created.Inputs.NonIndexed().Pack(big.NewInt(500), big.NewInt(2), creator, uint64(1700))
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	evt, err := decodeEvent(parsed, types.Log{
+		Topics:      []common.Hash{created.ID, common.BigToHash(big.NewInt(6)), common.BytesToHash(token.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        data,
+		BlockNumber: 42,
+		Index:       3,
+	})
+	if err != nil {
+		t.Fatalf("decode RequestCreated: %v", err)
+	}
+	rc, ok := evt.(RequestCreatedEvent)
+	if !ok {
+		t.Fatalf("got %T want RequestCreatedEvent", evt)
+	}
+	if rc.ID.Uint64() != 6 || rc.Token != token || rc.To != to || rc.Amount.Int64() != 500 {
+		t.Fatalf("unexpected indexed fields %+v", rc)
+	}
+	if rc.ApprovalsNeeded != 2 || rc.CreatedBy != creator || rc.EarliestExec != 1700 || rc.BlockNumber != 42 || rc.LogIndex != 3 {
+		t.Fatalf("unexpected payload fields %+v", rc)
+	}
+
+	batch := parsed.Events["BatchExecuted"]
+	data, err = batch.Inputs.NonIndexed().Pack([]*big.Int{big.NewInt(1), big.NewInt(4)}, big.NewInt(90000))
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	evt, err = decodeEvent(parsed, types.Log{Topics: []common.Hash{batch.ID, common.BytesToHash(creator.Bytes())}, Data: data})
+	if err != nil {
+		t.Fatalf("decode BatchExecuted: %v", err)
+	}
+	be := evt.(BatchExecutedEvent)
+	if len(be.IDsProcessed) != 2 || be.IDsProcessed[1].Uint64() != 4 || be.Executor != creator || be.GasUsed != 90000 {
+		t.Fatalf("unexpected batch event %+v", be)
+	}
+
+	granted := parsed.Events["RoleGranted"]
+	evt, err = decodeEvent(parsed, types.Log{Topics: []common.Hash{granted.ID, GuardianRole, common.BytesToHash(to.Bytes()), common.BytesToHash(creator.Bytes())}})
+	if err != nil {
+		t.Fatalf("decode RoleGranted: %v", err)
+	}
+	rg := evt.(RoleGrantedEvent)
+	if RoleName(rg.Role) != "GUARDIAN_ROLE" || rg.Account != to || rg.Sender != creator {
+		t.Fatalf("unexpected role event %+v", rg)
+	}
+
+	paused := parsed.Events["Paused"]
+	data, err = paused.Inputs.NonIndexed().Pack(creator)
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	evt, err = decodeEvent(parsed, types.Log{Topics: []common.Hash{paused.ID}, Data: data})
+	if err != nil {
+		t.Fatalf("decode Paused: %v", err)
+	}
+	if evt.(PausedEvent).Account != creator {
+		t.Fatalf("unexpected paused account")
+	}
+
+	if _, err := decodeEvent(parsed, types.Log{Topics: []common.Hash{common.HexToHash("0xdead")}}); err == nil {
+		t.Fatalf("expected unknown topic to fail")
+	}
+}
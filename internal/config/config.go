@@ -8,18 +8,62 @@ import (
 	"time"
 )
 
+const (
+	ModeActive   = "active"
+	ModeObserver = "observer"
+	ModeDryRun   = "dry-run"
+)
+
 type Config struct {
+	Mode string
+
 	RPCUrl          string
 	WSUrl           string
 	ChainID         uint64
 	ContractAddress string
 
-	GuardianKey string
-	ExecutorKey string
+	RPCUrls          []string
+	WSUrls           []string
+	RPCQuorum        int
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	GuardianKey Secret
+	ExecutorKey Secret
+
+	GuardianKeystore     string
+	GuardianPasswordFile string
+	ExecutorKeystore     string
+	ExecutorPasswordFile string
+	GuardianSignerURL    string
+	GuardianAddress      string
+	ExecutorSignerURL    string
+	ExecutorAddress      string
+	SignerMethod         string
+	MinSignerBalance     string
+	SignerCheckAction    string
 
-	MaxBatch     int
-	PollInterval time.Duration
-	GasFloor     uint64
+	MaxBatch          int
+	PollInterval      time.Duration
+	ReconcileInterval time.Duration
+	GasFloor          uint64
+
+	GasMultiplier        float64
+	ApproveGasMax        uint64
+	ExecuteGasPerRequest uint64
+
+	FeeBumpBlocks  uint64
+	FeeBumpPercent uint64
+	MaxFeePerGas   string
+	MaxTxFee       string
+
+	StartBlock      uint64
+	LogChunkSize    uint64
+	DataDir         string
+	Confirmations   uint64
+	ConfirmationTag string
+	LogPollInterval time.Duration
+	WSMaxFailures   int
 
 	HTTPListenAddr   string
 	LogLevel         string
@@ -28,7 +72,15 @@ type Config struct {
 
 	PolicyMaxAmount     string
 	PolicyAllowedTokens []string
+	PolicyCooldown      time.Duration
+	PolicyFile          string
+	PriceMaxAge         time.Duration
+	PriceMaxDeviation   float64
 	Network             string
+
+	RecipientDenylistFile     string
+	RecipientCodeCheck        bool
+	RecipientAllowedContracts []string
 }
 
 func Load() Config {
@@ -36,18 +88,56 @@ func Load() Config {
 
 	cfg := Config{}
 
+	cfg.Mode = getenvDefault("GUARDD_MODE", ModeActive)
+
 	cfg.RPCUrl = getenvDefault("RPC_URL", "http://127.0.0.1:8545")
-	cfg.WSUrl = getenvDefault("WS_URL", "ws://127.0.0.1:8545")
+	cfg.WSUrl = getenvOptional("WS_URL", "ws://127.0.0.1:8545")
 	cfg.ChainID = getenvUint64("CHAIN_ID", 31337)
 	cfg.ContractAddress = getenvDefault("CONTRACT_ADDRESS", "0x0000000000000000000000000000000000000000")
 
-	cfg.GuardianKey = getenvDefault("GUARDIAN_KEY", "")
-	cfg.ExecutorKey = getenvDefault("EXECUTOR_KEY", "")
+	cfg.RPCUrls = splitCSV(getenvDefault("RPC_URLS", ""))
+	cfg.WSUrls = splitCSV(getenvDefault("WS_URLS", ""))
+	cfg.RPCQuorum = getenvInt("RPC_QUORUM", 1)
+	cfg.BreakerThreshold = getenvInt("BREAKER_THRESHOLD", 3)
+	cfg.BreakerCooldown = getenvDuration("BREAKER_COOLDOWN", 30*time.Second)
+
+	cfg.GuardianKey = Secret(getenvDefault("GUARDIAN_KEY", ""))
+	cfg.ExecutorKey = Secret(getenvDefault("EXECUTOR_KEY", ""))
+
+	cfg.GuardianKeystore = getenvDefault("GUARDIAN_KEYSTORE", "")
+	cfg.GuardianPasswordFile = getenvDefault("GUARDIAN_PASSWORD_FILE", "")
+	cfg.ExecutorKeystore = getenvDefault("EXECUTOR_KEYSTORE", "")
+	cfg.ExecutorPasswordFile = getenvDefault("EXECUTOR_PASSWORD_FILE", "")
+	cfg.GuardianSignerURL = getenvDefault("GUARDIAN_SIGNER_URL", "")
+	cfg.GuardianAddress = getenvDefault("GUARDIAN_ADDRESS", "")
+	cfg.ExecutorSignerURL = getenvDefault("EXECUTOR_SIGNER_URL", "")
+	cfg.ExecutorAddress = getenvDefault("EXECUTOR_ADDRESS", "")
+	cfg.SignerMethod = getenvDefault("SIGNER_METHOD", "eth_signTransaction")
+	cfg.MinSignerBalance = getenvDefault("MIN_SIGNER_BALANCE", "1000000000000000")
+	cfg.SignerCheckAction = getenvDefault("SIGNER_CHECK_ACTION", "refuse")
 
 	cfg.MaxBatch = getenvInt("MAX_BATCH", 10)
 	cfg.PollInterval = getenvDuration("POLL_INTERVAL", 5*time.Second)
+	cfg.ReconcileInterval = getenvDuration("RECONCILE_INTERVAL", 5*time.Minute)
 	cfg.GasFloor = getenvUint64("GAS_FLOOR", 50000)
 
+	cfg.GasMultiplier = getenvFloat("GAS_MULTIPLIER", 1.25)
+	cfg.ApproveGasMax = getenvUint64("APPROVE_GAS_MAX", 200000)
+	cfg.ExecuteGasPerRequest = getenvUint64("EXECUTE_GAS_PER_REQUEST", 150000)
+
+	cfg.FeeBumpBlocks = getenvUint64("FEE_BUMP_BLOCKS", 3)
+	cfg.FeeBumpPercent = getenvUint64("FEE_BUMP_PERCENT", 15)
+	cfg.MaxFeePerGas = getenvDefault("MAX_FEE_PER_GAS", "100000000000")
+	cfg.MaxTxFee = getenvDefault("MAX_TX_FEE", "0")
+
+	cfg.StartBlock = getenvUint64("START_BLOCK", 0)
+	cfg.LogChunkSize = getenvUint64("LOG_CHUNK_SIZE", 2000)
+	cfg.DataDir = getenvDefault("DATA_DIR", "data")
+	cfg.Confirmations = getenvUint64("CONFIRMATIONS", 2)
+	cfg.ConfirmationTag = getenvDefault("CONFIRMATION_TAG", "latest")
+	cfg.LogPollInterval = getenvDuration("LOG_POLL_INTERVAL", 2*time.Second)
+	cfg.WSMaxFailures = getenvInt("WS_MAX_FAILURES", 3)
+
 	cfg.HTTPListenAddr = getenvDefault("HTTP_LISTEN_ADDR", "127.0.0.1:9000")
 	cfg.LogLevel = getenvDefault("LOG_LEVEL", "info")
 	cfg.MetricsNamespace = getenvDefault("METRICS_NAMESPACE", "treasury_guard")
@@ -55,6 +145,13 @@ func Load() Config {
 
 	cfg.PolicyMaxAmount = getenvDefault("POLICY_MAX_AMOUNT", "0")
 	cfg.PolicyAllowedTokens = splitCSV(getenvDefault("POLICY_ALLOWED_TOKENS", ""))
+	cfg.PolicyCooldown = getenvDuration("POLICY_COOLDOWN", 0)
+	cfg.PolicyFile = getenvDefault("POLICY_FILE", "")
+	cfg.PriceMaxAge = getenvDuration("PRICE_MAX_AGE", time.Hour)
+	cfg.PriceMaxDeviation = getenvFloat("PRICE_MAX_DEVIATION", 0.1)
+	cfg.RecipientDenylistFile = getenvDefault("RECIPIENT_DENYLIST_FILE", "")
+	cfg.RecipientCodeCheck = getenvBool("RECIPIENT_CODE_CHECK", true)
+	cfg.RecipientAllowedContracts = splitCSV(getenvDefault("RECIPIENT_ALLOWED_CONTRACTS", ""))
 	cfg.Network = getenvDefault("NETWORK", "base-sepolia")
 
 	return cfg
@@ -97,6 +194,16 @@ func getenvDefault(key, fallback string) string {
 	return value
 }
 
+// getenvOptional treats a variable that is set but empty as an explicit
+// empty value rather than falling back to the default.
+func getenvOptional(key, fallback string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	return strings.TrimSpace(value)
+}
+
 func getenvUint64(key string, fallback uint64) uint64 {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
@@ -121,6 +228,30 @@ func getenvInt(key string, fallback int) int {
 	return parsed
 }
 
+func getenvFloat(key string, fallback float64) float64 {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func getenvBool(key string, fallback bool) bool {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
 func getenvDuration(key string, fallback time.Duration) time.Duration {
 	value := strings.TrimSpace(os.Getenv(key))
 	if value == "" {
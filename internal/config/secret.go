@@ -0,0 +1,26 @@
+package config
+
+// Secret holds key material. It prints and marshals as a placeholder so a
+// logged or dumped Config never exposes it; Reveal returns the value.
+type Secret string
+
+const redacted = "[redacted]"
+
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
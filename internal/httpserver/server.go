@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 
@@ -10,6 +11,7 @@ import (
 
 type Server struct {
 	httpServer *http.Server
+	mux        *http.ServeMux
 }
 
 func Start(addr string, metricsHandler http.Handler, log *zap.Logger) *Server {
@@ -38,7 +40,24 @@ func Start(addr string, metricsHandler http.Handler, log *zap.Logger) *Server {
 		}
 	}()
 
-	return &Server{httpServer: srv}
+	return &Server{httpServer: srv, mux: mux}
+}
+
+// Handle adds a route after the server has started.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// JSON serves the value returned by fn as JSON on every GET.
+func JSON(fn func() interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(fn())
+	})
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -12,6 +13,27 @@ type Registry struct {
 	approvalsTotal  prometheus.Counter
 	executionsTotal prometheus.Counter
 	failuresTotal   prometheus.Counter
+	driftTotal      prometheus.Counter
+	reorgsTotal     prometheus.Counter
+	revertsTotal    prometheus.Counter
+	gasUsedTotal    prometheus.Counter
+	skippedTotal    prometheus.Counter
+	replacedTotal   prometheus.Counter
+	feeCappedTotal  prometheus.Counter
+	nonceGapsTotal  prometheus.Counter
+	simSkipsTotal   prometheus.Counter
+	escalatedTotal  prometheus.Counter
+	deferredTotal   prometheus.Counter
+	deferred        prometheus.Gauge
+	intentsTotal    *prometheus.CounterVec
+	policyRejected  *prometheus.CounterVec
+	policyReloads   *prometheus.CounterVec
+	policyVersion   *prometheus.GaugeVec
+	executedAmount  *prometheus.CounterVec
+	denylistSize    prometheus.Gauge
+	rpcLatency      *prometheus.GaugeVec
+	rpcErrorRate    *prometheus.GaugeVec
+	rpcBreakerOpen  *prometheus.GaugeVec
 }
 
 func NewRegistry(namespace string) *Registry {
@@ -21,12 +43,12 @@ func NewRegistry(namespace string) *Registry {
 	approvals := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: namespace,
 		Name:      "approvals_total",
-		Help:      "Total approvals sent",
+		Help:      "Total approval transactions mined successfully",
 	})
 	executions := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: namespace,
 		Name:      "executions_total",
-		Help:      "Total executeBatch calls sent",
+		Help:      "Total executeBatch transactions mined successfully",
 	})
 	failures := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: namespace,
@@ -34,13 +56,157 @@ func NewRegistry(namespace string) *Registry {
 		Help:      "Total transaction failures",
 	})
 
-	reg.MustRegister(approvals, executions, failures)
+	drift := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_drift_total",
+		Help:      "Total requests whose local state differed from the contract during reconciliation",
+	})
+
+	reorgs := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reorgs_total",
+		Help:      "Total contract logs rolled back by chain reorganizations",
+	})
+
+	reverts := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_reverted_total",
+		Help:      "Total sent transactions whose receipt reported a revert",
+	})
+
+	gasUsed := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_gas_used_total",
+		Help:      "Total gas used by mined transactions",
+	})
+
+	skipped := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "batch_skipped_total",
+		Help:      "Total request ids skipped by executeBatch and requeued",
+	})
+
+	replaced := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_replacements_total",
+		Help:      "Total stuck transactions re-sent with bumped fees",
+	})
+
+	feeCapped := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_fee_cap_reached_total",
+		Help:      "Total replacement attempts stopped by MAX_FEE_PER_GAS",
+	})
+
+	nonceGaps := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "nonce_gaps_total",
+		Help:      "Total dropped nonces detected and queued for reuse",
+	})
+
+	simSkips := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "simulation_skipped_total",
+		Help:      "Total approve and executeBatch sends skipped because simulation reverted",
+	})
+
+	escalated := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "escalations_total",
+		Help:      "Total failures that need operator attention, such as a missing role or no gas funds",
+	})
+
+	deferredTotal := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tx_deferred_total",
+		Help:      "Total approvals and executions deferred because the fee was over MAX_TX_FEE",
+	})
+	deferred := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tx_deferred",
+		Help:      "Approvals and executions currently waiting for fees to drop",
+	})
+
+	intents := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "intents_total",
+		Help:      "Total approvals and executions decided on but not sent, by action",
+	}, []string{"action"})
+
+	policyRejected := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "policy_rejections_total",
+		Help:      "Total requests the policy engine refused to approve, by reason",
+	}, []string{"reason"})
+
+	policyReloads := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "policy_reloads_total",
+		Help:      "Total policy file reloads, by result",
+	}, []string{"result"})
+
+	policyVersion := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "policy_version_info",
+		Help:      "Set to 1 for the active policy file version",
+	}, []string{"version"})
+
+	executedAmount := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "executed_amount_total",
+		Help:      "Total amount paid out by executed requests, in whole token units",
+	}, []string{"token", "symbol"})
+
+	denylistSize := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "recipient_denylist_size",
+		Help:      "Addresses on the loaded recipient denylist",
+	})
+
+	rpcLatency := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_endpoint_latency_seconds",
+		Help:      "Smoothed call latency per RPC endpoint",
+	}, []string{"endpoint"})
+	rpcErrorRate := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_endpoint_error_rate",
+		Help:      "Smoothed transport error rate per RPC endpoint",
+	}, []string{"endpoint"})
+	rpcBreakerOpen := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_endpoint_breaker_open",
+		Help:      "1 while the endpoint's circuit breaker is open",
+	}, []string{"endpoint"})
+
+	reg.MustRegister(approvals, executions, failures, drift, reorgs, reverts, gasUsed, skipped, replaced, feeCapped, nonceGaps, simSkips, escalated, deferredTotal, deferred, intents, policyRejected, policyReloads, policyVersion, executedAmount, denylistSize, rpcLatency, rpcErrorRate, rpcBreakerOpen)
 
 	return &Registry{
 		registry:        reg,
 		approvalsTotal:  approvals,
 		executionsTotal: executions,
 		failuresTotal:   failures,
+		driftTotal:      drift,
+		reorgsTotal:     reorgs,
+		revertsTotal:    reverts,
+		gasUsedTotal:    gasUsed,
+		skippedTotal:    skipped,
+		replacedTotal:   replaced,
+		feeCappedTotal:  feeCapped,
+		nonceGapsTotal:  nonceGaps,
+		simSkipsTotal:   simSkips,
+		escalatedTotal:  escalated,
+		deferredTotal:   deferredTotal,
+		deferred:        deferred,
+		intentsTotal:    intents,
+		policyRejected:  policyRejected,
+		policyReloads:   policyReloads,
+		policyVersion:   policyVersion,
+		executedAmount:  executedAmount,
+		denylistSize:    denylistSize,
+		rpcLatency:      rpcLatency,
+		rpcErrorRate:    rpcErrorRate,
+		rpcBreakerOpen:  rpcBreakerOpen,
 	}
 }
 
@@ -60,6 +226,89 @@ func (r *Registry) IncFailures() {
 	r.failuresTotal.Inc()
 }
 
+func (r *Registry) IncReconcileDrift() {
+	r.driftTotal.Inc()
+}
+
+func (r *Registry) IncReorgs() {
+	r.reorgsTotal.Inc()
+}
+
+func (r *Registry) IncReverts() {
+	r.revertsTotal.Inc()
+}
+
+func (r *Registry) AddGasUsed(gas uint64) {
+	r.gasUsedTotal.Add(float64(gas))
+}
+
+func (r *Registry) IncBatchSkipped() {
+	r.skippedTotal.Inc()
+}
+
+func (r *Registry) IncReplacements() {
+	r.replacedTotal.Inc()
+}
+
+func (r *Registry) IncFeeCapReached() {
+	r.feeCappedTotal.Inc()
+}
+
+func (r *Registry) AddNonceGaps(n int) {
+	r.nonceGapsTotal.Add(float64(n))
+}
+
+func (r *Registry) IncSimulationSkips() {
+	r.simSkipsTotal.Inc()
+}
+
+func (r *Registry) IncEscalations() {
+	r.escalatedTotal.Inc()
+}
+
+func (r *Registry) IncDeferrals() {
+	r.deferredTotal.Inc()
+}
+
+func (r *Registry) SetDeferred(n int) {
+	r.deferred.Set(float64(n))
+}
+
+func (r *Registry) IncIntents(action string) {
+	r.intentsTotal.WithLabelValues(action).Inc()
+}
+
+func (r *Registry) IncPolicyRejections(reason string) {
+	r.policyRejected.WithLabelValues(reason).Inc()
+}
+
+func (r *Registry) IncPolicyReloads(result string) {
+	r.policyReloads.WithLabelValues(result).Inc()
+}
+
+func (r *Registry) SetPolicyVersion(version string) {
+	r.policyVersion.Reset()
+	r.policyVersion.WithLabelValues(version).Set(1)
+}
+
+func (r *Registry) AddExecutedAmount(token, symbol string, amount float64) {
+	r.executedAmount.WithLabelValues(token, symbol).Add(amount)
+}
+
+func (r *Registry) SetDenylistSize(n int) {
+	r.denylistSize.Set(float64(n))
+}
+
+func (r *Registry) SetEndpointHealth(endpoint string, latency time.Duration, errorRate float64, breakerOpen bool) {
+	r.rpcLatency.WithLabelValues(endpoint).Set(latency.Seconds())
+	r.rpcErrorRate.WithLabelValues(endpoint).Set(errorRate)
+	open := 0.0
+	if breakerOpen {
+		open = 1
+	}
+	r.rpcBreakerOpen.WithLabelValues(endpoint).Set(open)
+}
+
 func register(reg *prometheus.Registry, collector prometheus.Collector) {
 	if err := reg.Register(collector); err != nil {
 		if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"base-treasury-guard/internal/client"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const stateFile = "guardd-state.json"
+
+type TxKind string
+
+const (
+	TxApprove TxKind = "approve"
+	TxExecute TxKind = "execute"
+)
+
+type TxRecord struct {
+	Hash      common.Hash `json:"hash"`
+	Kind      TxKind      `json:"kind"`
+	IDs       []uint64    `json:"ids"`
+	SentAt    time.Time   `json:"sentAt"`
+	SentBlock uint64      `json:"sentBlock"`
+	// Replaced holds earlier hashes for the same nonce; any of them may
+	// still be the one that mines.
+	Replaced []common.Hash `json:"replaced,omitempty"`
+}
+
+func (t TxRecord) Hashes() []common.Hash {
+	return append([]common.Hash{t.Hash}, t.Replaced...)
+}
+
+// SpendRecord is an approved or executed request counted against the
+// rolling spend limits.
+type SpendRecord struct {
+	ID        uint64         `json:"id"`
+	Token     common.Address `json:"token"`
+	To        common.Address `json:"to"`
+	CreatedBy common.Address `json:"createdBy"`
+	Amount    *big.Int       `json:"amount"`
+	At        time.Time      `json:"at"`
+}
+
+type snapshot struct {
+	Version   int                                  `json:"version"`
+	LastBlock uint64                               `json:"lastBlock"`
+	Requests  map[uint64]client.RequestState       `json:"requests"`
+	Txs       map[common.Hash]TxRecord             `json:"txs"`
+	Nonces    map[common.Address]client.NonceState `json:"nonces"`
+	Spends    map[uint64]SpendRecord               `json:"spends"`
+}
+
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	state snapshot
+	dirty bool
+}
+
+func NewMemory() *Store {
+	return &Store{state: emptySnapshot()}
+}
+
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, errors.New("data dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	s := &Store{path: filepath.Join(dir, stateFile), state: emptySnapshot()}
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var loaded snapshot
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", s.path, err)
+	}
+	if loaded.Requests == nil {
+		loaded.Requests = make(map[uint64]client.RequestState)
+	}
+	if loaded.Txs == nil {
+		loaded.Txs = make(map[common.Hash]TxRecord)
+	}
+	if loaded.Nonces == nil {
+		loaded.Nonces = make(map[common.Address]client.NonceState)
+	}
+	if loaded.Spends == nil {
+		loaded.Spends = make(map[uint64]SpendRecord)
+	}
+	s.state = loaded
+	return s, nil
+}
+
+func emptySnapshot() snapshot {
+	return snapshot{
+		Version:  1,
+		Requests: make(map[uint64]client.RequestState),
+		Txs:      make(map[common.Hash]TxRecord),
+		Nonces:   make(map[common.Address]client.NonceState),
+		Spends:   make(map[uint64]SpendRecord),
+	}
+}
+
+func (s *Store) Path() string {
+	return s.path
+}
+
+func (s *Store) LastBlock() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.LastBlock
+}
+
+func (s *Store) SetLastBlock(block uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if block <= s.state.LastBlock {
+		return
+	}
+	s.state.LastBlock = block
+	s.dirty = true
+}
+
+func (s *Store) PutRequest(req client.RequestState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prev, ok := s.state.Requests[req.ID]; ok && sameRequest(prev, req) {
+		return
+	}
+	s.state.Requests[req.ID] = req
+	s.dirty = true
+}
+
+// ForgetRequest drops a finalized request. Transactions that touched it stay
+// recorded until their receipt has been processed.
+func (s *Store) ForgetRequest(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.state.Requests[id]; ok {
+		delete(s.state.Requests, id)
+		s.dirty = true
+	}
+}
+
+func (s *Store) Requests() []client.RequestState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]client.RequestState, 0, len(s.state.Requests))
+	for _, req := range s.state.Requests {
+		out = append(out, req)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (s *Store) PutTx(tx TxRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Txs[tx.Hash] = tx
+	s.dirty = true
+}
+
+func (s *Store) DeleteTx(hash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.state.Txs[hash]; !ok {
+		return
+	}
+	delete(s.state.Txs, hash)
+	s.dirty = true
+}
+
+func (s *Store) HasTx(kind TxKind, id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tx := range s.state.Txs {
+		if tx.Kind != kind {
+			continue
+		}
+		for _, txID := range tx.IDs {
+			if txID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Store) Txs() []TxRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]TxRecord, 0, len(s.state.Txs))
+	for _, tx := range s.state.Txs {
+		out = append(out, tx)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SentAt.Before(out[j].SentAt) })
+	return out
+}
+
+func (s *Store) Nonce(signer common.Address) (client.NonceState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.state.Nonces[signer]
+	return state, ok
+}
+
+func (s *Store) PutNonce(signer common.Address, state client.NonceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Nonces[signer] = state
+	s.dirty = true
+}
+
+func (s *Store) Spends() []SpendRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]SpendRecord, 0, len(s.state.Spends))
+	for _, rec := range s.state.Spends {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (s *Store) PutSpend(rec SpendRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Spends[rec.ID] = rec
+	s.dirty = true
+}
+
+func (s *Store) DeleteSpend(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.state.Spends[id]; !ok {
+		return
+	}
+	delete(s.state.Spends, id)
+	s.dirty = true
+}
+
+// Checkpoint writes the current state if anything changed since the last
+// write. The file is replaced atomically so a crash mid-write leaves the
+// previous checkpoint intact.
+func (s *Store) Checkpoint() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty || s.path == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, raw); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+func sameRequest(a, b client.RequestState) bool {
+	amountA, amountB := a.Amount, b.Amount
+	a.Amount, b.Amount = nil, nil
+	if a != b {
+		return false
+	}
+	if amountA == nil || amountB == nil {
+		return amountA == amountB
+	}
+	return amountA.Cmp(amountB) == 0
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"base-treasury-guard/internal/client"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	req := client.RequestState{ID: 7, Amount: big.NewInt(42), ApprovalsNeeded: 1, ExpiresAt: 1000}
+	hash := common.HexToHash("0x01")
+	s.SetLastBlock(120)
+	s.PutRequest(req)
+	s.PutTx(TxRecord{Hash: hash, Kind: TxApprove, IDs: []uint64{7}, SentAt: time.Unix(100, 0)})
+	s.PutSpend(SpendRecord{ID: 7, Amount: big.NewInt(42), At: time.Unix(100, 0)})
+	if err := s.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if reopened.LastBlock() != 120 {
+		t.Fatalf("got last block %d want 120", reopened.LastBlock())
+	}
+	reqs := reopened.Requests()
+	if len(reqs) != 1 || reqs[0].ID != 7 || reqs[0].Amount.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	if !reopened.HasTx(TxApprove, 7) {
+		t.Fatalf("expected approve tx to survive restart")
+	}
+	spends := reopened.Spends()
+	if len(spends) != 1 || spends[0].Amount.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("unexpected spends %+v", spends)
+	}
+}
+
+func TestForgetRequestKeepsInFlightTxs(t *testing.T) {
+	s := NewMemory()
+	s.PutRequest(client.RequestState{ID: 1, Amount: big.NewInt(1)})
+	s.PutRequest(client.RequestState{ID: 2, Amount: big.NewInt(1)})
+	s.PutTx(TxRecord{Hash: common.HexToHash("0x0a"), Kind: TxExecute, IDs: []uint64{1, 2}})
+	s.PutTx(TxRecord{Hash: common.HexToHash("0x0b"), Kind: TxApprove, IDs: []uint64{1}})
+
+	s.ForgetRequest(1)
+	if len(s.Requests()) != 1 {
+		t.Fatalf("expected request 1 to be dropped")
+	}
+	if !s.HasTx(TxApprove, 1) || !s.HasTx(TxExecute, 1) {
+		t.Fatalf("expected txs to stay until their receipts are processed")
+	}
+
+	s.DeleteTx(common.HexToHash("0x0a"))
+	if s.HasTx(TxExecute, 2) || len(s.Txs()) != 1 {
+		t.Fatalf("unexpected txs after delete")
+	}
+}
@@ -0,0 +1,52 @@
+package watcher
+
+import (
+	"time"
+
+	"base-treasury-guard/internal/store"
+)
+
+type deferKey struct {
+	kind store.TxKind
+	id   uint64
+}
+
+// deferQueue remembers approvals and executions held back because their fee
+// was over MAX_TX_FEE. Deferred work is retried every tick until it fits.
+type deferQueue struct {
+	since map[deferKey]time.Time
+}
+
+func newDeferQueue() *deferQueue {
+	return &deferQueue{since: make(map[deferKey]time.Time)}
+}
+
+// add reports whether id was not already deferred for kind.
+func (q *deferQueue) add(kind store.TxKind, id uint64, now time.Time) bool {
+	key := deferKey{kind, id}
+	if _, ok := q.since[key]; ok {
+		return false
+	}
+	q.since[key] = now
+	return true
+}
+
+// resolve removes id and returns how long it waited.
+func (q *deferQueue) resolve(kind store.TxKind, id uint64, now time.Time) (time.Duration, bool) {
+	key := deferKey{kind, id}
+	since, ok := q.since[key]
+	if !ok {
+		return 0, false
+	}
+	delete(q.since, key)
+	return now.Sub(since), true
+}
+
+func (q *deferQueue) forget(id uint64) {
+	delete(q.since, deferKey{store.TxApprove, id})
+	delete(q.since, deferKey{store.TxExecute, id})
+}
+
+func (q *deferQueue) len() int {
+	return len(q.since)
+}
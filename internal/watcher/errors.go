@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"errors"
+
+	"base-treasury-guard/internal/client"
+)
+
+type errorAction int
+
+const (
+	// actionRetry covers transient causes: the same call may succeed later.
+	actionRetry errorAction = iota
+	// actionDrop means the call is no longer needed for this request.
+	actionDrop
+	// actionEscalate needs an operator, e.g. a missing role or no funds.
+	actionEscalate
+	// actionDefer holds the call until fees are back under MAX_TX_FEE.
+	actionDefer
+)
+
+func (a errorAction) String() string {
+	switch a {
+	case actionDrop:
+		return "drop"
+	case actionEscalate:
+		return "escalate"
+	case actionDefer:
+		return "defer"
+	default:
+		return "retry"
+	}
+}
+
+// actionFor decides how to react to a failed or skipped send.
+func actionFor(err error) errorAction {
+	switch {
+	case errors.Is(err, client.ErrFeeAboveCeiling):
+		return actionDefer
+	case errors.Is(err, client.ErrAlreadyApproved),
+		errors.Is(err, client.ErrNotPending),
+		errors.Is(err, client.ErrRequestExpired),
+		errors.Is(err, client.ErrInvalidID),
+		errors.Is(err, client.ErrTokenNotAllowed),
+		errors.Is(err, client.ErrAmountExceedsCap):
+		return actionDrop
+	case errors.Is(err, client.ErrUnauthorized),
+		errors.Is(err, client.ErrInsufficientFunds),
+		errors.Is(err, client.ErrGasAboveBlockLimit),
+		errors.Is(err, client.ErrUnknownAccount):
+		return actionEscalate
+	default:
+		return actionRetry
+	}
+}
@@ -0,0 +1,91 @@
+package watcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"base-treasury-guard/internal/client"
+
+	"go.uber.org/zap"
+)
+
+// maxIntents bounds how many recent intents are kept for the API.
+const maxIntents = 100
+
+// Intent is an approve or executeBatch the watcher decided on but did not
+// send, with the reason it was held back. Dry runs also carry the gas limit
+// and worst case fee in wei the transaction would have used.
+type Intent struct {
+	Action string    `json:"action"`
+	IDs    []uint64  `json:"ids"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+	Gas    uint64    `json:"gas,omitempty"`
+	Fee    string    `json:"fee,omitempty"`
+}
+
+type intentLog struct {
+	mu    sync.Mutex
+	items []Intent
+}
+
+func (l *intentLog) add(intent Intent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = append(l.items, intent)
+	if len(l.items) > maxIntents {
+		l.items = append([]Intent(nil), l.items[len(l.items)-maxIntents:]...)
+	}
+}
+
+func (l *intentLog) list() []Intent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Intent, len(l.items))
+	copy(out, l.items)
+	return out
+}
+
+// Intents returns the most recent actions the watcher would have sent,
+// oldest first.
+func (w *Watcher) Intents() []Intent {
+	return w.intents.list()
+}
+
+// recordIntent logs and counts an action that was not sent. A batch that is
+// still the same as the last one recorded is not recorded again, so a ready
+// batch does not repeat on every tick.
+func (w *Watcher) recordIntent(intent Intent) {
+	if intent.Action == "execute" {
+		key := idsKey(intent.IDs)
+		if key == w.lastExecIntent {
+			return
+		}
+		w.lastExecIntent = key
+	}
+	intent.IDs = append([]uint64(nil), intent.IDs...)
+	intent.At = time.Now()
+	w.intents.add(intent)
+	w.metrics.IncIntents(intent.Action)
+	w.log.Info("would "+intent.Action,
+		zap.Uint64s("ids", intent.IDs),
+		zap.String("reason", intent.Reason),
+		zap.Uint64("gas", intent.Gas),
+		zap.String("fee", intent.Fee),
+	)
+}
+
+// dryRunIntent describes a send that was simulated and sized but not
+// broadcast.
+func dryRunIntent(action string, ids []uint64, res client.SendResult) Intent {
+	intent := Intent{Action: action, IDs: ids, Reason: "dry run", Gas: res.Gas}
+	if res.Fee != nil {
+		intent.Fee = res.Fee.String()
+	}
+	return intent
+}
+
+func idsKey(ids []uint64) string {
+	return fmt.Sprint(ids)
+}
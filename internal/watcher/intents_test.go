@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/config"
+	"base-treasury-guard/internal/metrics"
+
+	"go.uber.org/zap"
+)
+
+func TestIntentsRecordedWithoutSending(t *testing.T) {
+	w := New(config.Config{Mode: config.ModeObserver}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.approveBlocked, w.executeBlocked = "observer mode", "observer mode"
+
+	// A blocked approval returns before the client is used.
+	w.approve(context.Background(), nil, 7)
+	w.recordIntent(Intent{Action: "execute", IDs: []uint64{1, 2}, Reason: w.executeBlocked})
+	w.recordIntent(Intent{Action: "execute", IDs: []uint64{1, 2}, Reason: w.executeBlocked})
+	w.recordIntent(dryRunIntent("execute", []uint64{2}, client.SendResult{DryRun: true, Gas: 210000, Fee: big.NewInt(42)}))
+
+	got := w.Intents()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 intents, got %+v", got)
+	}
+	if got[0].Action != "approve" || got[0].IDs[0] != 7 || got[0].Reason != "observer mode" {
+		t.Fatalf("unexpected approve intent %+v", got[0])
+	}
+	if got[1].Action != "execute" || len(got[1].IDs) != 2 || got[2].IDs[0] != 2 || got[2].Gas != 210000 || got[2].Fee != "42" {
+		t.Fatalf("unexpected execute intents %+v", got[1:])
+	}
+	if len(w.state.Txs()) != 0 {
+		t.Fatalf("observer must not record sent transactions")
+	}
+}
+
+func TestDryRunLeavesStateAndPolicyUntouched(t *testing.T) {
+	dir := t.TempDir()
+	w := New(config.Config{Mode: config.ModeDryRun, DataDir: dir, PolicyCooldown: time.Minute}, zap.NewNop(), metrics.NewRegistry("test"))
+	state, err := w.openState()
+	if err != nil {
+		t.Fatalf("open state: %v", err)
+	}
+	state.SetLastBlock(10)
+	if err := state.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Fatalf("expected dry run not to write DATA_DIR, got %v %v", entries, err)
+	}
+
+	// Approvals that are only simulated must not start a cooldown.
+	req := client.RequestState{ID: 3, Amount: big.NewInt(1)}
+	for i := 0; i < 2; i++ {
+		if !w.screen(context.Background(), &fakeScreen{}, req) {
+			t.Fatalf("expected unsent approval %d not to trip the cooldown", i)
+		}
+	}
+	w.policy.Record(w.policyRequest(req))
+	next := req
+	next.ID = 4
+	if d := w.evaluatePolicy(next); d.Reason != ReasonCooldownActive {
+		t.Fatalf("expected a sent approval to start the cooldown, got %+v", d)
+	}
+}
@@ -0,0 +1,123 @@
+package watcher
+
+import (
+	"sort"
+
+	"base-treasury-guard/internal/client"
+)
+
+const (
+	statusPending   uint8 = 0
+	statusCancelled uint8 = 1
+	statusExecuted  uint8 = 2
+	statusExpired   uint8 = 3
+)
+
+// requestBook is the watcher's local copy of every pending request. Contract
+// events move requests through their lifecycle so readiness can be decided
+// without a GetRequest call per request per tick.
+type requestBook struct {
+	requests map[uint64]client.RequestState
+}
+
+func newRequestBook() *requestBook {
+	return &requestBook{requests: make(map[uint64]client.RequestState)}
+}
+
+func (b *requestBook) track(req client.RequestState) {
+	b.requests[req.ID] = req
+}
+
+func (b *requestBook) get(id uint64) (client.RequestState, bool) {
+	req, ok := b.requests[id]
+	return req, ok
+}
+
+func (b *requestBook) has(id uint64) bool {
+	_, ok := b.requests[id]
+	return ok
+}
+
+func (b *requestBook) remove(id uint64) {
+	delete(b.requests, id)
+}
+
+func (b *requestBook) len() int {
+	return len(b.requests)
+}
+
+func (b *requestBook) ids() []uint64 {
+	ids := make([]uint64, 0, len(b.requests))
+	for id := range b.requests {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// apply folds a lifecycle event into the book and returns the updated
+// requests. Events for untracked ids are ignored.
+func (b *requestBook) apply(evt client.Event) []client.RequestState {
+	var changed []client.RequestState
+	update := func(id uint64, fn func(*client.RequestState)) {
+		req, ok := b.requests[id]
+		if !ok {
+			return
+		}
+		fn(&req)
+		b.requests[id] = req
+		changed = append(changed, req)
+	}
+
+	switch e := evt.(type) {
+	case client.RequestApprovedEvent:
+		if e.ID.IsUint64() {
+			update(e.ID.Uint64(), func(req *client.RequestState) {
+				if e.ApprovalsCount > req.Approvals {
+					req.Approvals = e.ApprovalsCount
+				}
+			})
+		}
+	case client.RequestCancelledEvent:
+		if e.ID.IsUint64() {
+			update(e.ID.Uint64(), func(req *client.RequestState) { req.Status = statusCancelled })
+		}
+	case client.RequestExpiredEvent:
+		if e.ID.IsUint64() {
+			update(e.ID.Uint64(), func(req *client.RequestState) { req.Status = statusExpired })
+		}
+	case client.RequestExecutedEvent:
+		if e.ID.IsUint64() {
+			update(e.ID.Uint64(), func(req *client.RequestState) { req.Status = statusExecuted })
+		}
+	case client.BatchExecutedEvent:
+		for _, id := range e.IDsProcessed {
+			if id.IsUint64() {
+				update(id.Uint64(), func(req *client.RequestState) { req.Status = statusExecuted })
+			}
+		}
+	}
+	return changed
+}
+
+// ready mirrors the contract's _isReady checks that can be evaluated locally.
+// Token balance is left to the contract.
+func isReady(req client.RequestState, now uint64) bool {
+	if req.Status != statusPending {
+		return false
+	}
+	if req.ApprovalsNeeded > 0 && req.Approvals < req.ApprovalsNeeded {
+		return false
+	}
+	if now < req.EarliestExec {
+		return false
+	}
+	if req.ExpiresAt > 0 && now > req.ExpiresAt {
+		return false
+	}
+	return true
+}
+
+func lapsed(req client.RequestState, now uint64) bool {
+	return req.Status == statusPending && req.ExpiresAt > 0 && now > req.ExpiresAt
+}
@@ -0,0 +1,45 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"base-treasury-guard/internal/client"
+)
+
+func TestRequestBookAppliesLifecycleEvents(t *testing.T) {
+	book := newRequestBook()
+	book.track(client.RequestState{ID: 1, Amount: big.NewInt(5), ApprovalsNeeded: 2, EarliestExec: 100, ExpiresAt: 500})
+	book.track(client.RequestState{ID: 2, Amount: big.NewInt(5), ApprovalsNeeded: 1, EarliestExec: 100, ExpiresAt: 500})
+
+	book.apply(client.RequestApprovedEvent{ID: big.NewInt(1), ApprovalsCount: 1})
+	req, _ := book.get(1)
+	if isReady(req, 200) {
+		t.Fatalf("expected request with 1/2 approvals to wait")
+	}
+
+	changed := book.apply(client.RequestApprovedEvent{ID: big.NewInt(1), ApprovalsCount: 2})
+	if len(changed) != 1 || changed[0].Approvals != 2 {
+		t.Fatalf("unexpected change set %+v", changed)
+	}
+	req, _ = book.get(1)
+	if isReady(req, 99) {
+		t.Fatalf("expected delay to be enforced")
+	}
+	if !isReady(req, 200) {
+		t.Fatalf("expected request to be ready")
+	}
+	if isReady(req, 501) || !lapsed(req, 501) {
+		t.Fatalf("expected request past expiry to lapse")
+	}
+
+	changed = book.apply(client.BatchExecutedEvent{IDsProcessed: []*big.Int{big.NewInt(2), big.NewInt(9)}})
+	if len(changed) != 1 || changed[0].ID != 2 || changed[0].Status != statusExecuted {
+		t.Fatalf("expected only tracked id 2 to be executed, got %+v", changed)
+	}
+
+	changed = book.apply(client.RequestCancelledEvent{ID: big.NewInt(1)})
+	if len(changed) != 1 || changed[0].Status != statusCancelled {
+		t.Fatalf("expected cancellation to apply")
+	}
+}
@@ -6,35 +6,108 @@ import (
     "sync"
     "time"
 
+    "base-treasury-guard/internal/client"
+
     "github.com/ethereum/go-ethereum/common"
 )
 
+const (
+    ReasonTokenNotAllowed     = "token_not_allowed"
+    ReasonAmountExceedsLimit  = "amount_exceeds_limit"
+    ReasonCooldownActive      = "cooldown_active"
+    ReasonRecipientNotAllowed = "recipient_not_allowed"
+    ReasonRecipientDenied     = "recipient_denied"
+    ReasonCreatorNotAllowed   = "creator_not_allowed"
+    ReasonPolicyNotLoaded     = "policy_not_loaded"
+    ReasonSpendLimitExceeded  = "spend_limit_exceeded"
+    ReasonLimitUnresolved     = "limit_unresolved"
+    ReasonPriceUnavailable    = "price_unavailable"
+    ReasonSelfTransfer        = "self_transfer"
+    ReasonRecipientDenylisted = "recipient_denylisted"
+    ReasonRecipientIsContract = "recipient_is_contract"
+    ReasonRecipientUnscreened = "recipient_unscreened"
+)
+
+// PolicyEngine decides whether the guardian approves a request. Decide
+// leaves rule state alone; Record is called once the approval was sent, so
+// cooldowns and spend limits only count approvals that reached the chain.
+type PolicyEngine interface {
+    Decide(req PolicyRequest) Decision
+    Record(req PolicyRequest)
+}
+
+// Decision is a policy verdict. Reason is a stable machine-readable code
+// used for metrics; Detail is for humans.
+type Decision struct {
+    Allow  bool
+    Rule   string
+    Reason string
+    Detail string
+}
+
+func allow() Decision {
+    return Decision{Allow: true}
+}
+
+func deny(rule, reason, detail string) Decision {
+    return Decision{Rule: rule, Reason: reason, Detail: detail}
+}
+
+// Retryable reports a denial caused by data that could not be fetched or
+// loaded rather than by the request itself. The request stays refused until
+// a later evaluation succeeds.
+func (d Decision) Retryable() bool {
+    switch d.Reason {
+    case ReasonPolicyNotLoaded, ReasonLimitUnresolved, ReasonPriceUnavailable, ReasonRecipientUnscreened:
+        return true
+    }
+    return false
+}
+
+// PolicyRule is one link in a Policy chain.
+type PolicyRule interface {
+    Name() string
+    Evaluate(req PolicyRequest) Decision
+}
+
+// policyRecorder is implemented by rules that keep state about approved
+// requests.
+type policyRecorder interface {
+    Record(req PolicyRequest)
+}
+
+// Policy runs its rules in order and stops at the first denial.
 type Policy struct {
-    allowlist map[common.Address]struct{}
-    maxAmount *big.Int
-    cooldown  time.Duration
-    now       func() time.Time
-    mu        sync.Mutex
-    lastSeen  map[string]time.Time
+    mu    sync.Mutex
+    rules []PolicyRule
 }
 
 type PolicyRequest struct {
+    ID        uint64
     Token     common.Address
     To        common.Address
     Amount    *big.Int
     CreatedBy common.Address
+    // Asset is the token's decimals and symbol, nil when they could not be
+    // fetched.
+    Asset *client.TokenInfo
+    // Price is a checked USD quote for the token, nil when the token has no
+    // feed or the quote failed; PriceErr then says why.
+    Price    *client.PriceQuote
+    PriceErr error
+    // ToHasCode reports whether To had contract code when the request was
+    // screened, nil when it was not looked up.
+    ToHasCode *bool
+}
+
+func NewPolicyChain(rules ...PolicyRule) *Policy {
+    return &Policy{rules: rules}
 }
 
 func NewPolicy(allowlistCSV string, maxAmountWei string, cooldown time.Duration, now func() time.Time) *Policy {
-    allowlist := make(map[common.Address]struct{})
+    var tokens []string
     if allowlistCSV != "" {
-        for _, entry := range strings.Split(allowlistCSV, ",") {
-            cleaned := strings.TrimSpace(entry)
-            if cleaned == "" {
-                continue
-            }
-            allowlist[common.HexToAddress(cleaned)] = struct{}{}
-        }
+        tokens = strings.Split(allowlistCSV, ",")
     }
 
     var maxAmount *big.Int
@@ -45,46 +118,138 @@ func NewPolicy(allowlistCSV string, maxAmountWei string, cooldown time.Duration,
         }
     }
 
-    if now == nil {
-        now = time.Now
+    return NewPolicyChain(
+        NewAllowlistRule(tokens),
+        MaxAmountRule{Max: maxAmount},
+        NewCooldownRule(cooldown, now),
+    )
+}
+
+// Evaluate decides req and records it when every rule allowed it.
+func (p *Policy) Evaluate(req PolicyRequest) Decision {
+    d := p.Decide(req)
+    if d.Allow {
+        p.Record(req)
     }
+    return d
+}
 
-    return &Policy{
-        allowlist: allowlist,
-        maxAmount: maxAmount,
-        cooldown:  cooldown,
-        now:       now,
-        lastSeen:  make(map[string]time.Time),
+func (p *Policy) Decide(req PolicyRequest) Decision {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    for _, rule := range p.rules {
+        if d := rule.Evaluate(req); !d.Allow {
+            if d.Rule == "" {
+                d.Rule = rule.Name()
+            }
+            return d
+        }
     }
+    return allow()
 }
 
-func (p *Policy) Check(req PolicyRequest) (bool, string) {
-    if len(p.allowlist) > 0 {
-        if _, ok := p.allowlist[req.Token]; !ok {
-            return false, "token_not_allowed"
+func (p *Policy) Record(req PolicyRequest) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    for _, rule := range p.rules {
+        if rec, ok := rule.(policyRecorder); ok {
+            rec.Record(req)
         }
     }
+}
 
-    if p.maxAmount != nil && req.Amount != nil {
-        if req.Amount.Cmp(p.maxAmount) > 0 {
-            return false, "amount_exceeds_limit"
+func (p *Policy) Check(req PolicyRequest) (bool, string) {
+    d := p.Evaluate(req)
+    return d.Allow, d.Reason
+}
+
+type AllowlistRule struct {
+    tokens map[common.Address]struct{}
+}
+
+func NewAllowlistRule(tokens []string) AllowlistRule {
+    allowlist := make(map[common.Address]struct{})
+    for _, entry := range tokens {
+        cleaned := strings.TrimSpace(entry)
+        if cleaned == "" {
+            continue
         }
+        allowlist[common.HexToAddress(cleaned)] = struct{}{}
     }
+    return AllowlistRule{tokens: allowlist}
+}
 
-    if p.cooldown > 0 {
-        key := req.Token.Hex() + ":" + req.To.Hex() + ":" + req.CreatedBy.Hex()
-        now := p.now()
+func (r AllowlistRule) Name() string { return "allowlist" }
 
-        p.mu.Lock()
-        defer p.mu.Unlock()
+func (r AllowlistRule) Evaluate(req PolicyRequest) Decision {
+    if len(r.tokens) == 0 {
+        return allow()
+    }
+    if _, ok := r.tokens[req.Token]; !ok {
+        return deny(r.Name(), ReasonTokenNotAllowed, "token "+req.Token.Hex()+" is not on the allowlist")
+    }
+    return allow()
+}
 
-        if last, ok := p.lastSeen[key]; ok {
-            if now.Sub(last) < p.cooldown {
-                return false, "cooldown_active"
-            }
+type MaxAmountRule struct {
+    Max *big.Int
+}
+
+func (r MaxAmountRule) Name() string { return "max_amount" }
+
+func (r MaxAmountRule) Evaluate(req PolicyRequest) Decision {
+    if r.Max == nil || r.Max.Sign() == 0 || req.Amount == nil {
+        return allow()
+    }
+    if req.Amount.Cmp(r.Max) > 0 {
+        return deny(r.Name(), ReasonAmountExceedsLimit, "amount "+formatAmount(req.Amount, req.Asset)+" exceeds "+formatAmount(r.Max, req.Asset))
+    }
+    return allow()
+}
+
+// CooldownRule rejects a request for the same token, recipient and creator
+// as one approved less than the cooldown ago. The approved request itself is
+// exempt, so re-evaluating it is not blocked by its own approval.
+type CooldownRule struct {
+    cooldown time.Duration
+    now      func() time.Time
+    lastSeen map[string]cooldownEntry
+}
+
+type cooldownEntry struct {
+    id uint64
+    at time.Time
+}
+
+func NewCooldownRule(cooldown time.Duration, now func() time.Time) *CooldownRule {
+    if now == nil {
+        now = time.Now
+    }
+    return &CooldownRule{cooldown: cooldown, now: now, lastSeen: make(map[string]cooldownEntry)}
+}
+
+func (r *CooldownRule) Name() string { return "cooldown" }
+
+func (r *CooldownRule) Evaluate(req PolicyRequest) Decision {
+    if r.cooldown <= 0 {
+        return allow()
+    }
+    if last, ok := r.lastSeen[cooldownKey(req)]; ok && last.id != req.ID {
+        if wait := r.cooldown - r.now().Sub(last.at); wait > 0 {
+            return deny(r.Name(), ReasonCooldownActive, "same token, recipient and creator within cooldown, "+wait.Round(time.Second).String()+" left")
         }
-        p.lastSeen[key] = now
     }
+    return allow()
+}
+
+func (r *CooldownRule) Record(req PolicyRequest) {
+    if r.cooldown > 0 {
+        r.lastSeen[cooldownKey(req)] = cooldownEntry{id: req.ID, at: r.now()}
+    }
+}
 
-    return true, ""
+func cooldownKey(req PolicyRequest) string {
+    return req.Token.Hex() + ":" + req.To.Hex() + ":" + req.CreatedBy.Hex()
 }
@@ -0,0 +1,407 @@
+package watcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"base-treasury-guard/internal/client"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// nativeToken is the key the policy file uses for ETH, which the contract
+// represents as address(0).
+const nativeToken = "native"
+
+var (
+	errPolicyNotLoaded = errors.New("policy file not loaded")
+	errNoTokenInfo     = errors.New("token decimals unknown")
+	errNoPrice         = errors.New("no usd price")
+)
+
+// usdUnit marks a policy amount in US dollars, converted with the token's
+// price feed.
+const usdUnit = "USD"
+
+// policyFileSpec is the on-disk layout of POLICY_FILE.
+type policyFileSpec struct {
+	Version    string                     `json:"version"`
+	Treasurers []string                   `json:"treasurers"`
+	Tokens     map[string]tokenPolicySpec `json:"tokens"`
+}
+
+type tokenPolicySpec struct {
+	MaxAmount      string           `json:"max_amount"`
+	Recipients     []string         `json:"recipients"`
+	DenyRecipients []string         `json:"deny_recipients"`
+	Daily          *spendWindowSpec `json:"daily"`
+	Weekly         *spendWindowSpec `json:"weekly"`
+	PriceFeed      string           `json:"price_feed"`
+	PriceMaxAge    string           `json:"price_max_age"`
+}
+
+// spendWindowSpec caps the cumulative amount approved or executed within a
+// rolling window, for the token as a whole and per recipient and creator.
+type spendWindowSpec struct {
+	Total        string `json:"total"`
+	PerRecipient string `json:"per_recipient"`
+	PerCreator   string `json:"per_creator"`
+}
+
+// filePolicy is a validated policy file.
+type filePolicy struct {
+	version    string
+	treasurers map[common.Address]struct{}
+	tokens     map[common.Address]tokenPolicy
+}
+
+type tokenPolicy struct {
+	maxAmount *policyAmount
+	allow     map[common.Address]struct{}
+	deny      map[common.Address]struct{}
+	limits    []spendLimit
+	feed      *common.Address
+	maxAge    time.Duration
+}
+
+// parsePolicyFile validates data and compiles it. Unknown fields, malformed
+// addresses and amounts, and recipients that are both allowed and denied are
+// errors. Without a version the content hash is used.
+func parsePolicyFile(data []byte) (*filePolicy, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var spec policyFileSpec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("decode policy file: %w", err)
+	}
+
+	p := &filePolicy{version: strings.TrimSpace(spec.Version), tokens: make(map[common.Address]tokenPolicy)}
+	if p.version == "" {
+		sum := sha256.Sum256(data)
+		p.version = "sha256:" + hex.EncodeToString(sum[:6])
+	}
+
+	treasurers, err := addressSet("treasurers", spec.Treasurers)
+	if err != nil {
+		return nil, err
+	}
+	p.treasurers = treasurers
+
+	for key, ts := range spec.Tokens {
+		token, err := policyToken(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := p.tokens[token]; dup {
+			return nil, fmt.Errorf("token %s listed twice", key)
+		}
+		tp := tokenPolicy{}
+		if ts.MaxAmount != "" {
+			amt, err := parsePolicyAmount(ts.MaxAmount)
+			if err != nil {
+				return nil, fmt.Errorf("token %s: max_amount: %w", key, err)
+			}
+			tp.maxAmount = &amt
+		}
+		if tp.allow, err = addressSet("token "+key+" recipients", ts.Recipients); err != nil {
+			return nil, err
+		}
+		if tp.deny, err = addressSet("token "+key+" deny_recipients", ts.DenyRecipients); err != nil {
+			return nil, err
+		}
+		for addr := range tp.deny {
+			if _, ok := tp.allow[addr]; ok {
+				return nil, fmt.Errorf("token %s: recipient %s is both allowed and denied", key, addr.Hex())
+			}
+		}
+		for _, w := range []struct {
+			name string
+			span time.Duration
+			spec *spendWindowSpec
+		}{{"daily", day, ts.Daily}, {"weekly", week, ts.Weekly}} {
+			if w.spec == nil {
+				continue
+			}
+			for _, l := range []struct {
+				scope, value string
+			}{{scopeToken, w.spec.Total}, {scopeRecipient, w.spec.PerRecipient}, {scopeCreator, w.spec.PerCreator}} {
+				if l.value == "" {
+					continue
+				}
+				max, err := parsePolicyAmount(l.value)
+				if err != nil {
+					return nil, fmt.Errorf("token %s: %s %s limit: %w", key, w.name, l.scope, err)
+				}
+				tp.limits = append(tp.limits, spendLimit{window: w.name, span: w.span, scope: l.scope, max: max})
+			}
+		}
+		if ts.PriceFeed != "" {
+			if !common.IsHexAddress(ts.PriceFeed) {
+				return nil, fmt.Errorf("token %s: invalid price_feed %q", key, ts.PriceFeed)
+			}
+			feed := common.HexToAddress(ts.PriceFeed)
+			tp.feed = &feed
+		}
+		if ts.PriceMaxAge != "" {
+			if tp.maxAge, err = time.ParseDuration(ts.PriceMaxAge); err != nil || tp.maxAge <= 0 {
+				return nil, fmt.Errorf("token %s: invalid price_max_age %q", key, ts.PriceMaxAge)
+			}
+		}
+		if tp.feed == nil && tp.usesUSD() {
+			return nil, fmt.Errorf("token %s: usd limits need a price_feed", key)
+		}
+		p.tokens[token] = tp
+	}
+	return p, nil
+}
+
+// policyAmount is a limit from the policy file, either in base units
+// ("5000000000") or in whole tokens with the symbol ("5000 USDC"). Whole
+// token amounts are converted once the token's decimals are known.
+type policyAmount struct {
+	raw   *big.Int
+	value string
+	unit  string
+}
+
+func parsePolicyAmount(s string) (policyAmount, error) {
+	fields := strings.Fields(s)
+	switch len(fields) {
+	case 1:
+		raw, ok := new(big.Int).SetString(fields[0], 10)
+		if !ok || raw.Sign() < 0 {
+			return policyAmount{}, fmt.Errorf("invalid amount %q, use base units or \"<amount> <SYMBOL>\"", s)
+		}
+		return policyAmount{raw: raw}, nil
+	case 2:
+		if _, err := client.ParseUnits(fields[0], 255); err != nil {
+			return policyAmount{}, err
+		}
+		return policyAmount{value: fields[0], unit: fields[1]}, nil
+	default:
+		return policyAmount{}, fmt.Errorf("invalid amount %q", s)
+	}
+}
+
+func (a policyAmount) isUSD() bool {
+	return strings.EqualFold(a.unit, usdUnit)
+}
+
+// resolve returns the amount in base units of the request's token. A whole
+// token amount needs the token metadata and its symbol must match the unit;
+// a USD amount also needs a checked price.
+func (a policyAmount) resolve(req PolicyRequest) (*big.Int, error) {
+	if a.raw != nil {
+		return a.raw, nil
+	}
+	asset := req.Asset
+	if asset == nil {
+		return nil, fmt.Errorf("%w for limit %s", errNoTokenInfo, a)
+	}
+	if !a.isUSD() {
+		if !strings.EqualFold(asset.Symbol, a.unit) {
+			return nil, fmt.Errorf("limit %s is not in %s", a, asset.Symbol)
+		}
+		return client.ParseUnits(a.value, asset.Decimals)
+	}
+	if req.Price == nil {
+		if req.PriceErr != nil {
+			return nil, fmt.Errorf("%w for limit %s: %v", errNoPrice, a, req.PriceErr)
+		}
+		return nil, fmt.Errorf("%w for limit %s", errNoPrice, a)
+	}
+	usd, err := client.ParseUnits(a.value, req.Price.Decimals)
+	if err != nil {
+		return nil, err
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil)
+	return usd.Mul(usd, scale).Quo(usd, req.Price.Answer), nil
+}
+
+// limitDenial turns a limit that could not be converted into a refusal.
+func limitDenial(rule string, err error) Decision {
+	if errors.Is(err, errNoPrice) {
+		return deny(rule, ReasonPriceUnavailable, err.Error())
+	}
+	return deny(rule, ReasonLimitUnresolved, err.Error())
+}
+
+func (a policyAmount) String() string {
+	if a.raw != nil {
+		return a.raw.String()
+	}
+	return a.value + " " + a.unit
+}
+
+// formatAmount shows amount in base units, followed by whole tokens when the
+// token is known.
+func formatAmount(amount *big.Int, asset *client.TokenInfo) string {
+	if asset == nil {
+		return amount.String()
+	}
+	return amount.String() + " (" + asset.Format(amount) + ")"
+}
+
+func (tp tokenPolicy) usesUSD() bool {
+	if tp.maxAmount != nil && tp.maxAmount.isUSD() {
+		return true
+	}
+	for _, l := range tp.limits {
+		if l.max.isUSD() {
+			return true
+		}
+	}
+	return false
+}
+
+func policyToken(key string) (common.Address, error) {
+	if strings.EqualFold(strings.TrimSpace(key), nativeToken) {
+		return common.Address{}, nil
+	}
+	if !common.IsHexAddress(key) {
+		return common.Address{}, fmt.Errorf("invalid token %q", key)
+	}
+	return common.HexToAddress(key), nil
+}
+
+func addressSet(field string, entries []string) (map[common.Address]struct{}, error) {
+	set := make(map[common.Address]struct{}, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !common.IsHexAddress(entry) {
+			return nil, fmt.Errorf("%s: invalid address %q", field, entry)
+		}
+		set[common.HexToAddress(entry)] = struct{}{}
+	}
+	return set, nil
+}
+
+// PolicyFileRule enforces POLICY_FILE. A token missing from the file is
+// refused, as is a request from a treasurer not listed when the file names
+// any. Reload swaps in a new version atomically and keeps the old one when
+// the new file does not validate.
+type PolicyFileRule struct {
+	path string
+
+	mu      sync.RWMutex
+	policy  *filePolicy
+	modTime time.Time
+	size    int64
+}
+
+func NewPolicyFileRule(path string) *PolicyFileRule {
+	return &PolicyFileRule{path: path}
+}
+
+func (r *PolicyFileRule) Name() string { return "policy_file" }
+
+// Version is the version of the active policy, empty before the first
+// successful load.
+func (r *PolicyFileRule) Version() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.policy == nil {
+		return ""
+	}
+	return r.policy.version
+}
+
+// Reload reads and validates the file if it changed since the last attempt.
+// changed is false when the file is untouched.
+func (r *PolicyFileRule) Reload() (changed bool, err error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	same := r.policy != nil && info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return false, err
+	}
+	p, err := parsePolicyFile(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modTime, r.size = info.ModTime(), info.Size()
+	if err != nil {
+		return true, err
+	}
+	r.policy = p
+	return true, nil
+}
+
+func (r *PolicyFileRule) spendLimits(token common.Address) []spendLimit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.policy == nil {
+		return nil
+	}
+	return r.policy.tokens[token].limits
+}
+
+// priceFeed returns the USD feed configured for token and how old its
+// answer may be, zero meaning the global default.
+func (r *PolicyFileRule) priceFeed(token common.Address) (common.Address, time.Duration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.policy == nil {
+		return common.Address{}, 0, false
+	}
+	tp, ok := r.policy.tokens[token]
+	if !ok || tp.feed == nil {
+		return common.Address{}, 0, false
+	}
+	return *tp.feed, tp.maxAge, true
+}
+
+func (r *PolicyFileRule) Evaluate(req PolicyRequest) Decision {
+	r.mu.RLock()
+	p := r.policy
+	r.mu.RUnlock()
+	if p == nil {
+		return deny(r.Name(), ReasonPolicyNotLoaded, errPolicyNotLoaded.Error())
+	}
+
+	if len(p.treasurers) > 0 {
+		if _, ok := p.treasurers[req.CreatedBy]; !ok {
+			return deny(r.Name(), ReasonCreatorNotAllowed, "creator "+req.CreatedBy.Hex()+" is not an allowed treasurer")
+		}
+	}
+	tp, ok := p.tokens[req.Token]
+	if !ok {
+		return deny(r.Name(), ReasonTokenNotAllowed, "token "+req.Token.Hex()+" is not in policy "+p.version)
+	}
+	if tp.maxAmount != nil && req.Amount != nil {
+		max, err := tp.maxAmount.resolve(req)
+		if err != nil {
+			return limitDenial(r.Name(), err)
+		}
+		if req.Amount.Cmp(max) > 0 {
+			return deny(r.Name(), ReasonAmountExceedsLimit, "amount "+formatAmount(req.Amount, req.Asset)+" exceeds "+formatAmount(max, req.Asset))
+		}
+	}
+	if _, ok := tp.deny[req.To]; ok {
+		return deny(r.Name(), ReasonRecipientDenied, "recipient "+req.To.Hex()+" is denied")
+	}
+	if len(tp.allow) > 0 {
+		if _, ok := tp.allow[req.To]; !ok {
+			return deny(r.Name(), ReasonRecipientNotAllowed, "recipient "+req.To.Hex()+" is not on the token allowlist")
+		}
+	}
+	return allow()
+}
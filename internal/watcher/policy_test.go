@@ -2,9 +2,14 @@ package watcher
 
 import (
     "math/big"
+    "os"
+    "path/filepath"
+    "strings"
     "testing"
     "time"
 
+    "base-treasury-guard/internal/client"
+
     "github.com/ethereum/go-ethereum/common"
 )
 
@@ -47,19 +52,197 @@ func TestPolicyCooldown(t *testing.T) {
     createdBy := common.HexToAddress("0x5555555555555555555555555555555555555555")
 
     policy := NewPolicy(token.Hex(), "", time.Minute, clock)
-    ok, _ := policy.Check(PolicyRequest{Token: token, To: to, CreatedBy: createdBy})
+    ok, _ := policy.Check(PolicyRequest{ID: 1, Token: token, To: to, CreatedBy: createdBy})
     if !ok {
         t.Fatalf("expected first request to pass")
     }
 
-    ok, _ = policy.Check(PolicyRequest{Token: token, To: to, CreatedBy: createdBy})
+    ok, _ = policy.Check(PolicyRequest{ID: 2, Token: token, To: to, CreatedBy: createdBy})
     if ok {
         t.Fatalf("expected cooldown rejection")
     }
 
+    ok, _ = policy.Check(PolicyRequest{ID: 1, Token: token, To: to, CreatedBy: createdBy})
+    if !ok {
+        t.Fatalf("expected the approved request not to be blocked by its own approval")
+    }
+
     now = now.Add(2 * time.Minute)
-    ok, _ = policy.Check(PolicyRequest{Token: token, To: to, CreatedBy: createdBy})
+    ok, _ = policy.Check(PolicyRequest{ID: 2, Token: token, To: to, CreatedBy: createdBy})
     if !ok {
         t.Fatalf("expected cooldown to expire")
     }
 }
+
+func TestPolicyChainStopsAtFirstDenial(t *testing.T) {
+    token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+    now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    cooldown := NewCooldownRule(time.Minute, func() time.Time { return now })
+    chain := NewPolicyChain(
+        NewAllowlistRule([]string{token.Hex()}),
+        MaxAmountRule{Max: big.NewInt(100)},
+        cooldown,
+    )
+
+    d := chain.Evaluate(PolicyRequest{Token: token, Amount: big.NewInt(101)})
+    if d.Allow || d.Reason != ReasonAmountExceedsLimit || d.Rule != "max_amount" {
+        t.Fatalf("expected max amount denial, got %+v", d)
+    }
+
+    d = chain.Evaluate(PolicyRequest{ID: 1, Token: token, Amount: big.NewInt(50)})
+    if !d.Allow {
+        t.Fatalf("expected request to pass, got %+v", d)
+    }
+
+    d = chain.Evaluate(PolicyRequest{ID: 2, Token: token, Amount: big.NewInt(50)})
+    if d.Allow || d.Reason != ReasonCooldownActive {
+        t.Fatalf("expected cooldown denial, got %+v", d)
+    }
+}
+
+func TestPolicyFileRuleEnforcesAndReloads(t *testing.T) {
+    token := common.HexToAddress("0x1111111111111111111111111111111111111111")
+    treasurer := common.HexToAddress("0x5555555555555555555555555555555555555555")
+    good := common.HexToAddress("0x6666666666666666666666666666666666666666")
+    bad := common.HexToAddress("0x7777777777777777777777777777777777777777")
+
+    path := filepath.Join(t.TempDir(), "policy.json")
+    write := func(body string, at time.Time) {
+        if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+            t.Fatalf("write: %v", err)
+        }
+        if err := os.Chtimes(path, at, at); err != nil {
+            t.Fatalf("chtimes: %v", err)
+        }
+    }
+    start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+    write(`{
+        "version": "v1",
+        "treasurers": ["`+treasurer.Hex()+`"],
+        "tokens": {
+            "native": {"max_amount": "100", "deny_recipients": ["`+bad.Hex()+`"]},
+            "`+token.Hex()+`": {"recipients": ["`+good.Hex()+`"]}
+        }
+    }`, start)
+
+    rule := NewPolicyFileRule(path)
+    if d := rule.Evaluate(PolicyRequest{}); d.Reason != ReasonPolicyNotLoaded {
+        t.Fatalf("expected fail closed before load, got %+v", d)
+    }
+    if changed, err := rule.Reload(); err != nil || !changed {
+        t.Fatalf("load: changed=%v err=%v", changed, err)
+    }
+    if rule.Version() != "v1" {
+        t.Fatalf("unexpected version %q", rule.Version())
+    }
+
+    cases := []struct {
+        req    PolicyRequest
+        reason string
+    }{
+        {PolicyRequest{Amount: big.NewInt(100), To: good, CreatedBy: treasurer}, ""},
+        {PolicyRequest{Amount: big.NewInt(101), To: good, CreatedBy: treasurer}, ReasonAmountExceedsLimit},
+        {PolicyRequest{Amount: big.NewInt(1), To: bad, CreatedBy: treasurer}, ReasonRecipientDenied},
+        {PolicyRequest{Amount: big.NewInt(1), To: good, CreatedBy: good}, ReasonCreatorNotAllowed},
+        {PolicyRequest{Token: token, Amount: big.NewInt(1), To: good, CreatedBy: treasurer}, ""},
+        {PolicyRequest{Token: token, Amount: big.NewInt(1), To: bad, CreatedBy: treasurer}, ReasonRecipientNotAllowed},
+        {PolicyRequest{Token: good, Amount: big.NewInt(1), To: good, CreatedBy: treasurer}, ReasonTokenNotAllowed},
+    }
+    for i, tc := range cases {
+        if d := rule.Evaluate(tc.req); d.Reason != tc.reason || d.Allow != (tc.reason == "") {
+            t.Fatalf("case %d: expected %q, got %+v", i, tc.reason, d)
+        }
+    }
+
+    if changed, _ := rule.Reload(); changed {
+        t.Fatalf("expected untouched file to be skipped")
+    }
+
+    write(`{"version": "v2", "tokens": {"native": {"max_amount": "-1"}}}`, start.Add(time.Minute))
+    if _, err := rule.Reload(); err == nil {
+        t.Fatalf("expected invalid file to be rejected")
+    }
+    if rule.Version() != "v1" {
+        t.Fatalf("expected previous policy to stay active, got %q", rule.Version())
+    }
+
+    write(`{"version": "v3", "tokens": {"native": {}}}`, start.Add(2*time.Minute))
+    if _, err := rule.Reload(); err != nil {
+        t.Fatalf("reload: %v", err)
+    }
+    if d := rule.Evaluate(PolicyRequest{Amount: big.NewInt(1000), To: bad, CreatedBy: good}); !d.Allow {
+        t.Fatalf("expected reloaded policy to allow, got %+v", d)
+    }
+}
+
+func TestPolicyFileHumanUnits(t *testing.T) {
+    usdc := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+    path := filepath.Join(t.TempDir(), "policy.json")
+    body := `{"tokens": {
+        "` + usdc.Hex() + `": {"max_amount": "5000 USDC"},
+        "native": {"max_amount": "0.5 ETH"}
+    }}`
+    if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+        t.Fatalf("write: %v", err)
+    }
+    rule := NewPolicyFileRule(path)
+    if _, err := rule.Reload(); err != nil {
+        t.Fatalf("load: %v", err)
+    }
+
+    asset := &client.TokenInfo{Address: usdc, Symbol: "USDC", Decimals: 6}
+    if d := rule.Evaluate(PolicyRequest{Token: usdc, Amount: big.NewInt(5_000_000_000), Asset: asset}); !d.Allow {
+        t.Fatalf("expected 5000 USDC to pass, got %+v", d)
+    }
+    d := rule.Evaluate(PolicyRequest{Token: usdc, Amount: big.NewInt(5_000_000_001), Asset: asset})
+    if d.Allow || d.Reason != ReasonAmountExceedsLimit || !strings.Contains(d.Detail, "5000.000001 USDC") {
+        t.Fatalf("expected over limit denial in whole units, got %+v", d)
+    }
+    if d := rule.Evaluate(PolicyRequest{Token: usdc, Amount: big.NewInt(1)}); d.Reason != ReasonLimitUnresolved {
+        t.Fatalf("expected unknown decimals to fail closed, got %+v", d)
+    }
+    wrong := &client.TokenInfo{Address: usdc, Symbol: "WETH", Decimals: 18}
+    if d := rule.Evaluate(PolicyRequest{Token: usdc, Amount: big.NewInt(1), Asset: wrong}); d.Reason != ReasonLimitUnresolved {
+        t.Fatalf("expected symbol mismatch to fail closed, got %+v", d)
+    }
+
+    eth := &client.TokenInfo{Symbol: "ETH", Decimals: 18}
+    half, _ := new(big.Int).SetString("500000000000000001", 10)
+    if d := rule.Evaluate(PolicyRequest{Amount: half, Asset: eth}); d.Reason != ReasonAmountExceedsLimit {
+        t.Fatalf("expected native limit in ETH, got %+v", d)
+    }
+
+    if _, err := parsePolicyFile([]byte(`{"tokens": {"native": {"max_amount": "0.5"}}}`)); err == nil {
+        t.Fatalf("expected fractional amount without unit to be rejected")
+    }
+}
+
+func TestPolicyFileUSDLimits(t *testing.T) {
+    feed := common.HexToAddress("0x71041dddad3595F9CEd3DcCFBe3D1F4b0a16Bb70")
+    p, err := parsePolicyFile([]byte(`{"tokens": {"native": {"max_amount": "1000 USD", "price_feed": "` + feed.Hex() + `"}}}`))
+    if err != nil {
+        t.Fatalf("parse: %v", err)
+    }
+    rule := &PolicyFileRule{policy: p}
+
+    eth := &client.TokenInfo{Symbol: "ETH", Decimals: 18}
+    price := &client.PriceQuote{Feed: feed, Decimals: 8, Answer: big.NewInt(2000_00000000)}
+    half, _ := new(big.Int).SetString("500000000000000000", 10)
+    if d := rule.Evaluate(PolicyRequest{Amount: half, Asset: eth, Price: price}); !d.Allow {
+        t.Fatalf("expected 0.5 ETH at $2000 to fit $1000, got %+v", d)
+    }
+    over := new(big.Int).Add(half, big.NewInt(1))
+    if d := rule.Evaluate(PolicyRequest{Amount: over, Asset: eth, Price: price}); d.Reason != ReasonAmountExceedsLimit {
+        t.Fatalf("expected usd limit denial, got %+v", d)
+    }
+
+    stale := client.ErrPriceStale
+    d := rule.Evaluate(PolicyRequest{Amount: big.NewInt(1), Asset: eth, PriceErr: stale})
+    if d.Allow || d.Reason != ReasonPriceUnavailable || !strings.Contains(d.Detail, stale.Error()) {
+        t.Fatalf("expected missing price to fail closed, got %+v", d)
+    }
+
+    if _, err := parsePolicyFile([]byte(`{"tokens": {"native": {"daily": {"total": "5000 USD"}}}}`)); err == nil {
+        t.Fatalf("expected usd limit without price feed to be rejected")
+    }
+}
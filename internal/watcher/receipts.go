@@ -0,0 +1,181 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type receiptSource interface {
+	Receipt(ctx context.Context, hash common.Hash) (*client.Receipt, error)
+}
+
+type receiptClient interface {
+	requestClient
+	receiptSource
+}
+
+// trackReceipts settles sent transactions once their receipt is buried under
+// the confirmed block. Metrics are only counted here, so a reverted or
+// dropped transaction never shows up as an approval or execution.
+func (w *Watcher) trackReceipts(ctx context.Context, ethClient receiptClient) {
+	for _, tx := range w.state.Txs() {
+		key := tx.Hash
+		receipt, err := w.minedReceipt(ctx, ethClient, tx)
+		if err != nil {
+			w.log.Error("receipt fetch failed", zap.String("tx", tx.Hash.Hex()), zap.Error(err))
+			w.metrics.IncFailures()
+			continue
+		}
+		if receipt == nil || receipt.BlockNumber > w.confirmedBlock {
+			continue
+		}
+		if receipt.TxHash != tx.Hash {
+			w.log.Info("earlier version of replaced tx mined", zap.String("tx", receipt.TxHash.Hex()), zap.String("latest", tx.Hash.Hex()))
+			tx.Hash = receipt.TxHash
+		}
+		w.metrics.AddGasUsed(receipt.GasUsed)
+		switch tx.Kind {
+		case store.TxApprove:
+			w.settleApprove(ctx, ethClient, tx, receipt)
+		case store.TxExecute:
+			w.settleExecute(ctx, ethClient, tx, receipt)
+		}
+		w.state.DeleteTx(key)
+	}
+}
+
+// minedReceipt returns the receipt of whichever version of tx mined, if any.
+func (w *Watcher) minedReceipt(ctx context.Context, ethClient receiptSource, tx store.TxRecord) (*client.Receipt, error) {
+	for _, hash := range tx.Hashes() {
+		receipt, err := ethClient.Receipt(ctx, hash)
+		if err != nil || receipt != nil {
+			return receipt, err
+		}
+	}
+	return nil, nil
+}
+
+// settleApprove counts a mined approval. A reverted one is re-read from the
+// contract and, if still pending, approved again; the retry simulates first,
+// so a revert that can never succeed is dropped or escalated by
+// approveFailed.
+func (w *Watcher) settleApprove(ctx context.Context, ethClient requestClient, tx store.TxRecord, receipt *client.Receipt) {
+	if !receipt.Succeeded {
+		w.metrics.IncReverts()
+		w.metrics.IncFailures()
+		w.log.Error("approve reverted", zap.Uint64s("ids", tx.IDs), zap.String("tx", tx.Hash.Hex()), zap.Uint64("gas_used", receipt.GasUsed))
+		for _, id := range tx.IDs {
+			w.refresh(ctx, ethClient, id)
+			w.reapprove(id, "approve reverted")
+		}
+		return
+	}
+	w.metrics.IncApprovals()
+	w.log.Info("approve mined", zap.Uint64s("ids", tx.IDs), zap.String("tx", tx.Hash.Hex()), zap.Uint64("block", receipt.BlockNumber), zap.Uint64("gas_used", receipt.GasUsed))
+}
+
+// settleExecute compares the batch that was sent with the ids the contract
+// reports in BatchExecuted. executeBatch skips ids that are not ready or whose
+// transfer fails without reverting, so those are requeued with a reason.
+func (w *Watcher) settleExecute(ctx context.Context, ethClient requestClient, tx store.TxRecord, receipt *client.Receipt) {
+	if !receipt.Succeeded {
+		w.metrics.IncReverts()
+		w.metrics.IncFailures()
+		w.log.Error("execute batch reverted", zap.Uint64s("ids", tx.IDs), zap.String("tx", tx.Hash.Hex()), zap.Uint64("gas_used", receipt.GasUsed))
+		for _, id := range tx.IDs {
+			w.requeue(id, "batch reverted")
+		}
+		return
+	}
+	w.metrics.IncExecutions()
+	if receipt.Batch != nil {
+		// The BatchExecuted log usually arrives through the event stream
+		// first; applying it again is a no-op for ids already finalized.
+		for _, req := range w.requests.apply(*receipt.Batch) {
+			w.updateRequest(req)
+		}
+	}
+	processed := make(map[uint64]struct{})
+	for _, id := range receipt.ProcessedIDs() {
+		processed[id] = struct{}{}
+	}
+	w.log.Info("execute batch mined",
+		zap.String("tx", tx.Hash.Hex()),
+		zap.Uint64("block", receipt.BlockNumber),
+		zap.Uint64("gas_used", receipt.GasUsed),
+		zap.Int("sent", len(tx.IDs)),
+		zap.Int("processed", len(processed)),
+	)
+
+	var skipped []uint64
+	for _, id := range tx.IDs {
+		if _, ok := processed[id]; !ok {
+			skipped = append(skipped, id)
+		}
+	}
+	if len(skipped) == 0 {
+		return
+	}
+	now, err := ethClient.ChainTime(ctx)
+	if err != nil {
+		w.log.Error("chain time fetch failed", zap.Error(err))
+		w.metrics.IncFailures()
+	}
+	for _, id := range skipped {
+		w.metrics.IncBatchSkipped()
+		req, err := ethClient.GetRequest(ctx, id)
+		if err != nil {
+			w.log.Error("request fetch failed", zap.Uint64("id", id), zap.Error(err))
+			w.metrics.IncFailures()
+			w.requeue(id, "skipped by contract")
+			continue
+		}
+		w.updateRequest(req)
+		if req.Status != statusPending {
+			continue
+		}
+		w.requeue(id, skipReason(req, now))
+	}
+}
+
+// requeue makes id eligible for the next batch once the regular cooldown has
+// passed again.
+func (w *Watcher) requeue(id uint64, reason string) {
+	if !w.requests.has(id) {
+		return
+	}
+	w.execCooldownUntil[id] = time.Now().Add(execCooldown)
+	w.log.Warn("request requeued", zap.Uint64("id", id), zap.String("reason", reason))
+}
+
+// reapprove schedules another approval attempt for a tracked request whose
+// approve transaction did not land.
+func (w *Watcher) reapprove(id uint64, reason string) {
+	if !w.requests.has(id) {
+		return
+	}
+	w.approveRetry[id] = time.Now().Add(execCooldown)
+	w.log.Warn("approve requeued", zap.Uint64("id", id), zap.String("reason", reason))
+}
+
+// skipReason explains why executeBatch passed over a still pending request.
+// Checks the contract makes that the daemon cannot see locally are reported
+// together.
+func skipReason(req client.RequestState, now uint64) string {
+	switch {
+	case req.ExpiresAt > 0 && now > req.ExpiresAt:
+		return "expired"
+	case req.Approvals < req.ApprovalsNeeded:
+		return "insufficient approvals"
+	case now > 0 && now < req.EarliestExec:
+		return "delay not met"
+	default:
+		return "insufficient balance, failed transfer or gas floor reached"
+	}
+}
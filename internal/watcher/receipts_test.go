@@ -0,0 +1,334 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/config"
+	"base-treasury-guard/internal/metrics"
+	"base-treasury-guard/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type fakeReceipts struct {
+	*fakeClient
+	receipts map[common.Hash]*client.Receipt
+}
+
+func (f *fakeReceipts) Receipt(ctx context.Context, hash common.Hash) (*client.Receipt, error) {
+	return f.receipts[hash], nil
+}
+
+func TestReceiptRequeuesSkippedBatchIDs(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	pending := func(id uint64) client.RequestState {
+		return client.RequestState{ID: id, Amount: big.NewInt(1), Approvals: 1, ApprovalsNeeded: 1, EarliestExec: 1, ExpiresAt: 1000}
+	}
+	for id := uint64(1); id <= 3; id++ {
+		w.requests.track(pending(id))
+	}
+
+	hash := common.HexToHash("0x01")
+	sentAt := time.Now().Add(-time.Minute)
+	w.state.PutTx(store.TxRecord{Hash: hash, Kind: store.TxExecute, IDs: []uint64{1, 2, 3}, SentAt: sentAt})
+
+	executed := pending(1)
+	executed.Status = statusExecuted
+	cancelled := pending(3)
+	cancelled.Status = statusCancelled
+	fc := &fakeReceipts{
+		fakeClient: &fakeClient{now: 10, reqs: map[uint64]client.RequestState{1: executed, 2: pending(2), 3: cancelled}},
+		receipts: map[common.Hash]*client.Receipt{
+			hash: {
+				TxHash:      hash,
+				Succeeded:   true,
+				GasUsed:     90000,
+				BlockNumber: 20,
+				Batch:       &client.BatchExecutedEvent{IDsProcessed: []*big.Int{big.NewInt(1)}},
+			},
+		},
+	}
+
+	if batch := w.buildReadyBatch(context.Background(), fc); len(batch) != 0 {
+		t.Fatalf("expected in-flight ids to stay out of new batches, got %v", batch)
+	}
+
+	w.confirmedBlock = 19
+	w.trackReceipts(context.Background(), fc)
+	if len(w.state.Txs()) != 1 {
+		t.Fatalf("expected tx to wait for receipt confirmations")
+	}
+
+	w.confirmedBlock = 20
+	w.trackReceipts(context.Background(), fc)
+	if len(w.state.Txs()) != 0 {
+		t.Fatalf("expected settled tx to be removed")
+	}
+	if w.requests.has(3) {
+		t.Fatalf("expected cancelled id to be dropped instead of requeued")
+	}
+	if !w.requests.has(2) {
+		t.Fatalf("expected skipped id 2 to stay tracked")
+	}
+	if until := w.execCooldownUntil[2]; !until.After(time.Now()) {
+		t.Fatalf("expected skipped id 2 to be requeued behind a cooldown")
+	}
+
+	w.execCooldownUntil[2] = time.Now().Add(-time.Second)
+	if batch := w.buildReadyBatch(context.Background(), fc); len(batch) != 1 || batch[0] != 2 {
+		t.Fatalf("expected requeued id 2 in the next batch, got %v", batch)
+	}
+}
+
+func TestRevertedApproveIsRetried(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	pending := client.RequestState{ID: 1, Amount: big.NewInt(1), ExpiresAt: 1000}
+	cancelled := client.RequestState{ID: 2, Amount: big.NewInt(1), ExpiresAt: 1000, Status: statusCancelled}
+	w.requests.track(pending)
+	w.requests.track(client.RequestState{ID: 2, Amount: big.NewInt(1), ExpiresAt: 1000})
+
+	first, second := common.HexToHash("0x11"), common.HexToHash("0x12")
+	w.state.PutTx(store.TxRecord{Hash: first, Kind: store.TxApprove, IDs: []uint64{1}})
+	w.state.PutTx(store.TxRecord{Hash: second, Kind: store.TxApprove, IDs: []uint64{2}})
+	fc := &fakeReceipts{
+		fakeClient: &fakeClient{now: 10, reqs: map[uint64]client.RequestState{1: pending, 2: cancelled}},
+		receipts: map[common.Hash]*client.Receipt{
+			first:  {TxHash: first, BlockNumber: 5},
+			second: {TxHash: second, BlockNumber: 5},
+		},
+	}
+	w.confirmedBlock = 5
+	w.trackReceipts(context.Background(), fc)
+
+	if len(w.state.Txs()) != 0 {
+		t.Fatalf("expected reverted approvals to be settled")
+	}
+	if _, ok := w.approveRetry[1]; !ok {
+		t.Fatalf("expected pending id 1 queued for another approval")
+	}
+	if _, ok := w.approveRetry[2]; ok || w.requests.has(2) {
+		t.Fatalf("expected cancelled id 2 to be dropped instead of retried")
+	}
+}
+
+func TestSkipReason(t *testing.T) {
+	base := client.RequestState{Approvals: 1, ApprovalsNeeded: 1, EarliestExec: 5, ExpiresAt: 100}
+	cases := []struct {
+		name string
+		mod  func(*client.RequestState)
+		now  uint64
+		want string
+	}{
+		{"expired", func(*client.RequestState) {}, 101, "expired"},
+		{"approvals", func(r *client.RequestState) { r.Approvals = 0 }, 10, "insufficient approvals"},
+		{"delay", func(*client.RequestState) {}, 4, "delay not met"},
+		{"contractSide", func(*client.RequestState) {}, 10, "insufficient balance, failed transfer or gas floor reached"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := base
+			tc.mod(&req)
+			if got := skipReason(req, tc.now); got != tc.want {
+				t.Fatalf("got %q want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+type fakeReplacer struct {
+	calls []common.Hash
+	next  common.Hash
+	err   error
+}
+
+func (f *fakeReplacer) Receipt(ctx context.Context, hash common.Hash) (*client.Receipt, error) {
+	return nil, nil
+}
+
+func (f *fakeReplacer) ReplaceTx(ctx context.Context, hash common.Hash) (client.Replacement, error) {
+	f.calls = append(f.calls, hash)
+	if f.err != nil {
+		return client.Replacement{}, f.err
+	}
+	return client.Replacement{Hash: f.next, GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(20)}, nil
+}
+
+func TestReplaceStuckAndSettleEarlierVersion(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10, FeeBumpBlocks: 3}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.requests.track(client.RequestState{ID: 1, Amount: big.NewInt(1), Approvals: 1, ApprovalsNeeded: 1, ExpiresAt: 1000})
+	original := common.HexToHash("0x0a")
+	w.state.PutTx(store.TxRecord{Hash: original, Kind: store.TxExecute, IDs: []uint64{1}, SentBlock: 10})
+
+	replacer := &fakeReplacer{next: common.HexToHash("0x0b")}
+	w.replaceStuck(context.Background(), replacer, 12)
+	if len(replacer.calls) != 0 {
+		t.Fatalf("expected no replacement before FEE_BUMP_BLOCKS")
+	}
+
+	w.replaceStuck(context.Background(), replacer, 13)
+	txs := w.state.Txs()
+	if len(txs) != 1 || txs[0].Hash != replacer.next || len(txs[0].Replaced) != 1 || txs[0].Replaced[0] != original {
+		t.Fatalf("expected record to move to the replacement hash, got %+v", txs)
+	}
+	if txs[0].SentBlock != 13 {
+		t.Fatalf("expected replacement to restart the block window, got %d", txs[0].SentBlock)
+	}
+
+	replacer.err = client.ErrFeeCapReached
+	w.replaceStuck(context.Background(), replacer, 16)
+	if txs := w.state.Txs(); len(txs) != 1 || txs[0].Hash != replacer.next || txs[0].SentBlock != 16 {
+		t.Fatalf("expected capped tx to keep its hash and wait another window, got %+v", txs)
+	}
+
+	replacer.err = client.ErrTxDropped
+	w.replaceStuck(context.Background(), replacer, 16)
+	if len(w.state.Txs()) != 1 {
+		t.Fatalf("expected tx to wait a full window before the next attempt")
+	}
+
+	// The original transaction wins the race for the nonce.
+	fc := &fakeReceipts{
+		fakeClient: &fakeClient{now: 10},
+		receipts: map[common.Hash]*client.Receipt{
+			original: {TxHash: original, Succeeded: true, BlockNumber: 17, Batch: &client.BatchExecutedEvent{IDsProcessed: []*big.Int{big.NewInt(1)}}},
+		},
+	}
+	w.confirmedBlock = 17
+	w.trackReceipts(context.Background(), fc)
+	if len(w.state.Txs()) != 0 {
+		t.Fatalf("expected tx settled through its earlier hash")
+	}
+	if w.requests.has(1) {
+		t.Fatalf("expected executed request to be finalized")
+	}
+}
+
+func TestReplaceStuckDropsUnknownTx(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10, FeeBumpBlocks: 3}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.requests.track(client.RequestState{ID: 4, Amount: big.NewInt(1), Approvals: 1, ApprovalsNeeded: 1, ExpiresAt: 1000})
+	w.state.PutTx(store.TxRecord{Hash: common.HexToHash("0x0c"), Kind: store.TxExecute, IDs: []uint64{4}})
+
+	w.replaceStuck(context.Background(), &fakeReplacer{err: client.ErrTxDropped}, 5)
+	if len(w.state.Txs()) != 0 {
+		t.Fatalf("expected dropped tx to be forgotten")
+	}
+	if until := w.execCooldownUntil[4]; !until.After(time.Now()) {
+		t.Fatalf("expected id 4 to be requeued")
+	}
+}
+
+func TestReplaceStuckRetriesDroppedApprove(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10, FeeBumpBlocks: 3}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.requests.track(client.RequestState{ID: 5, Amount: big.NewInt(1), ExpiresAt: 1000})
+	w.state.PutTx(store.TxRecord{Hash: common.HexToHash("0x0f"), Kind: store.TxApprove, IDs: []uint64{5}})
+
+	w.replaceStuck(context.Background(), &fakeReplacer{err: client.ErrTxDropped}, 5)
+	if len(w.state.Txs()) != 0 || w.state.HasTx(store.TxApprove, 5) {
+		t.Fatalf("expected dropped approve to be forgotten")
+	}
+	if _, ok := w.approveRetry[5]; !ok {
+		t.Fatalf("expected id 5 queued for another approval")
+	}
+	if _, ok := w.execCooldownUntil[5]; ok {
+		t.Fatalf("expected a dropped approve not to touch the batch cooldown")
+	}
+}
+
+type fakeHead uint64
+
+func (f *fakeHead) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(*f), nil
+}
+
+func TestReplaceStuckFollowsChainHead(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10, FeeBumpBlocks: 3}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.requests.track(client.RequestState{ID: 2, Amount: big.NewInt(1), ExpiresAt: 1000})
+	head := fakeHead(100)
+	if err := w.trackHead(context.Background(), &head); err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	w.state.PutTx(store.TxRecord{Hash: common.HexToHash("0x0d"), Kind: store.TxApprove, IDs: []uint64{2}, SentBlock: w.head})
+
+	// The contract emits nothing, so only the chain head moves.
+	replacer := &fakeReplacer{next: common.HexToHash("0x0e")}
+	for head < 103 {
+		head++
+		w.trackHead(context.Background(), &head)
+		w.replaceStuck(context.Background(), replacer, w.head)
+	}
+	if len(replacer.calls) != 1 {
+		t.Fatalf("expected one replacement after FEE_BUMP_BLOCKS, got %d", len(replacer.calls))
+	}
+	if txs := w.state.Txs(); len(txs) != 1 || txs[0].Hash != replacer.next || txs[0].SentBlock != 103 {
+		t.Fatalf("unexpected record %+v", txs)
+	}
+}
+
+func TestBatchFailureActions(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	pending := func(id uint64) client.RequestState {
+		return client.RequestState{ID: id, Amount: big.NewInt(1), Approvals: 1, ApprovalsNeeded: 1, EarliestExec: 1, ExpiresAt: 1000}
+	}
+	w.requests.track(pending(1))
+	w.requests.track(pending(2))
+
+	executed := pending(1)
+	executed.Status = statusExecuted
+	fc := &fakeClient{now: 10, reqs: map[uint64]client.RequestState{1: executed}}
+
+	paused := &client.RevertError{Reason: "EnforcedPause()", Err: client.ErrPaused}
+	w.batchFailed(context.Background(), fc, []uint64{2}, paused, "execute batch skipped")
+	if _, ok := w.execCooldownUntil[2]; !ok {
+		t.Fatalf("expected paused batch to be requeued")
+	}
+
+	notPending := &client.RevertError{Reason: "NOT_PENDING", Err: client.ErrNotPending}
+	w.batchFailed(context.Background(), fc, []uint64{1}, notPending, "execute batch skipped")
+	if w.requests.has(1) {
+		t.Fatalf("expected executed request to be dropped")
+	}
+	if _, ok := w.execCooldownUntil[1]; ok {
+		t.Fatalf("dropped request must not be requeued")
+	}
+
+	if got := actionFor(fmt.Errorf("send: %w", client.ErrUnauthorized)); got != actionEscalate {
+		t.Fatalf("unauthorized: got %s, want escalate", got)
+	}
+	if got := actionFor(client.ErrUnderpriced); got != actionRetry {
+		t.Fatalf("underpriced: got %s, want retry", got)
+	}
+}
+
+func TestBatchDeferredUntilFeesDrop(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	for id := uint64(1); id <= 2; id++ {
+		w.requests.track(client.RequestState{ID: id, Amount: big.NewInt(1), Approvals: 1, ApprovalsNeeded: 1, EarliestExec: 1, ExpiresAt: 1000})
+	}
+	fc := &fakeClient{now: 10}
+
+	overBudget := &client.FeeCeilingError{Fee: big.NewInt(2), L1Fee: big.NewInt(1), Ceiling: big.NewInt(1)}
+	for i := 0; i < 2; i++ {
+		w.batchFailed(context.Background(), fc, []uint64{1, 2}, fmt.Errorf("send: %w", overBudget), "execute batch failed")
+	}
+	if w.deferred.len() != 2 {
+		t.Fatalf("expected both ids deferred once, got %d", w.deferred.len())
+	}
+	if len(w.execCooldownUntil) != 0 {
+		t.Fatalf("deferred ids must be retried next tick, not cooled down")
+	}
+	if batch := w.buildReadyBatch(context.Background(), fc); len(batch) != 2 {
+		t.Fatalf("expected deferred ids in the next batch, got %v", batch)
+	}
+
+	w.resume(store.TxExecute, []uint64{1})
+	w.forget(2)
+	if w.deferred.len() != 0 {
+		t.Fatalf("expected deferral queue empty, got %d", w.deferred.len())
+	}
+}
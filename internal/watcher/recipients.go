@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// parseDenylist reads one address per line. Blank lines and anything after
+// a '#' are ignored, and a malformed address rejects the whole file so a
+// truncated download never silently shrinks the list.
+func parseDenylist(data []byte) (map[common.Address]struct{}, error) {
+	set := make(map[common.Address]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !common.IsHexAddress(line) {
+			return nil, fmt.Errorf("denylist line %d: invalid address %q", n, line)
+		}
+		set[common.HexToAddress(line)] = struct{}{}
+	}
+	return set, scanner.Err()
+}
+
+// RecipientRule screens the payout address. It refuses the TreasuryGuard
+// contract and the daemon's own signers, anything on the denylist file, and,
+// when code checks are on, any address with contract code that is not on
+// the contract allowlist.
+type RecipientRule struct {
+	denylistPath string
+	checkCode    bool
+	contracts    map[common.Address]struct{}
+
+	mu       sync.RWMutex
+	self     map[common.Address]string
+	denylist map[common.Address]struct{}
+	modTime  time.Time
+	size     int64
+}
+
+func NewRecipientRule(denylistPath string, checkCode bool, allowedContracts []string) *RecipientRule {
+	r := &RecipientRule{
+		denylistPath: denylistPath,
+		checkCode:    checkCode,
+		contracts:    make(map[common.Address]struct{}),
+		self:         make(map[common.Address]string),
+	}
+	for _, entry := range allowedContracts {
+		if common.IsHexAddress(entry) {
+			r.contracts[common.HexToAddress(entry)] = struct{}{}
+		}
+	}
+	return r
+}
+
+func (r *RecipientRule) Name() string { return "recipient_screen" }
+
+// Protect refuses payouts to addr, labelled for the rejection detail.
+func (r *RecipientRule) Protect(addr common.Address, label string) {
+	if addr == (common.Address{}) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.self[addr] = label
+}
+
+// Reload re-reads the denylist if it changed since the last attempt. A file
+// that fails to parse keeps the previous list.
+func (r *RecipientRule) Reload() (changed bool, size int, err error) {
+	if r.denylistPath == "" {
+		return false, 0, nil
+	}
+	info, err := os.Stat(r.denylistPath)
+	if err != nil {
+		return false, 0, err
+	}
+	r.mu.RLock()
+	same := r.denylist != nil && info.ModTime().Equal(r.modTime) && info.Size() == r.size
+	r.mu.RUnlock()
+	if same {
+		return false, 0, nil
+	}
+
+	data, err := os.ReadFile(r.denylistPath)
+	if err != nil {
+		return false, 0, err
+	}
+	list, err := parseDenylist(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.modTime, r.size = info.ModTime(), info.Size()
+	if err != nil {
+		return true, len(r.denylist), err
+	}
+	r.denylist = list
+	return true, len(list), nil
+}
+
+func (r *RecipientRule) Evaluate(req PolicyRequest) Decision {
+	r.mu.RLock()
+	label, self := r.self[req.To]
+	_, denied := r.denylist[req.To]
+	loaded := r.denylist != nil
+	r.mu.RUnlock()
+
+	if self {
+		return deny(r.Name(), ReasonSelfTransfer, "recipient "+req.To.Hex()+" is the "+label)
+	}
+	if r.denylistPath != "" && !loaded {
+		return deny(r.Name(), ReasonRecipientUnscreened, "denylist not loaded")
+	}
+	if denied {
+		return deny(r.Name(), ReasonRecipientDenylisted, "recipient "+req.To.Hex()+" is on the denylist")
+	}
+	if !r.checkCode {
+		return allow()
+	}
+	if _, ok := r.contracts[req.To]; ok {
+		return allow()
+	}
+	if req.ToHasCode == nil {
+		return deny(r.Name(), ReasonRecipientUnscreened, "could not check recipient "+req.To.Hex()+" for contract code")
+	}
+	if *req.ToHasCode {
+		return deny(r.Name(), ReasonRecipientIsContract, "recipient "+req.To.Hex()+" is a contract not on the allowlist")
+	}
+	return allow()
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRecipientScreening(t *testing.T) {
+	contract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	signer := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	sanctioned := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	safe := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	other := common.HexToAddress("0x5555555555555555555555555555555555555555")
+	eoa := common.HexToAddress("0x6666666666666666666666666666666666666666")
+
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(path, []byte("# compliance feed\n"+sanctioned.Hex()+" # ofac\n\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	rule := NewRecipientRule(path, true, []string{safe.Hex()})
+	rule.Protect(contract, "TreasuryGuard contract")
+	rule.Protect(signer, "guardd signer")
+
+	yes, no := true, false
+	if d := rule.Evaluate(PolicyRequest{To: eoa, ToHasCode: &no}); d.Reason != ReasonRecipientUnscreened {
+		t.Fatalf("expected unloaded denylist to fail closed, got %+v", d)
+	}
+	if _, size, err := rule.Reload(); err != nil || size != 1 {
+		t.Fatalf("load: size=%d err=%v", size, err)
+	}
+
+	cases := []struct {
+		name   string
+		req    PolicyRequest
+		reason string
+	}{
+		{"contract itself", PolicyRequest{To: contract, ToHasCode: &yes}, ReasonSelfTransfer},
+		{"own signer", PolicyRequest{To: signer, ToHasCode: &no}, ReasonSelfTransfer},
+		{"denylisted", PolicyRequest{To: sanctioned, ToHasCode: &no}, ReasonRecipientDenylisted},
+		{"unknown contract", PolicyRequest{To: other, ToHasCode: &yes}, ReasonRecipientIsContract},
+		{"allowlisted contract", PolicyRequest{To: safe, ToHasCode: &yes}, ""},
+		{"code not checked", PolicyRequest{To: eoa}, ReasonRecipientUnscreened},
+		{"plain account", PolicyRequest{To: eoa, ToHasCode: &no}, ""},
+	}
+	for _, tc := range cases {
+		if d := rule.Evaluate(tc.req); d.Reason != tc.reason || d.Allow != (tc.reason == "") {
+			t.Fatalf("%s: expected %q, got %+v", tc.name, tc.reason, d)
+		}
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.WriteFile(path, []byte("not-an-address\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if _, _, err := rule.Reload(); err == nil {
+		t.Fatalf("expected malformed denylist to be rejected")
+	}
+	if d := rule.Evaluate(PolicyRequest{To: sanctioned, ToHasCode: &no}); d.Reason != ReasonRecipientDenylisted {
+		t.Fatalf("expected previous denylist to stay active, got %+v", d)
+	}
+}
@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type txReplacer interface {
+	receiptSource
+	ReplaceTx(ctx context.Context, hash common.Hash) (client.Replacement, error)
+}
+
+// replaceStuck re-sends transactions that have not mined within
+// FEE_BUMP_BLOCKS of being sent, reusing the nonce with bumped fees. The
+// previous hash stays on the record so whichever version mines is settled.
+func (w *Watcher) replaceStuck(ctx context.Context, ethClient txReplacer, head uint64) {
+	if w.cfg.FeeBumpBlocks == 0 {
+		return
+	}
+	for _, tx := range w.state.Txs() {
+		if head < tx.SentBlock+w.cfg.FeeBumpBlocks {
+			continue
+		}
+		repl, err := ethClient.ReplaceTx(ctx, tx.Hash)
+		switch {
+		case errors.Is(err, client.ErrTxNotPending):
+			// Mined and waiting for confirmations; the receipt tracker
+			// settles it.
+			continue
+		case errors.Is(err, client.ErrTxDropped):
+			w.dropUnknown(ctx, ethClient, tx)
+			continue
+		case errors.Is(err, client.ErrFeeCapReached):
+			w.metrics.IncFeeCapReached()
+			w.log.Warn("stuck tx at max fee, not replacing", zap.String("tx", tx.Hash.Hex()), zap.Uint64s("ids", tx.IDs), zap.Error(err))
+			tx.SentBlock = head
+			w.state.PutTx(tx)
+			continue
+		case err != nil:
+			w.metrics.IncFailures()
+			w.log.Error("tx replacement failed", zap.String("tx", tx.Hash.Hex()), zap.Error(err))
+			continue
+		}
+
+		w.state.DeleteTx(tx.Hash)
+		prev := tx.Hash
+		tx.Replaced = append(append([]common.Hash(nil), tx.Replaced...), prev)
+		tx.Hash = repl.Hash
+		tx.SentAt = time.Now()
+		tx.SentBlock = head
+		w.state.PutTx(tx)
+		w.metrics.IncReplacements()
+		w.log.Info("stuck tx replaced",
+			zap.String("kind", string(tx.Kind)),
+			zap.String("old_tx", prev.Hex()),
+			zap.String("tx", repl.Hash.Hex()),
+			zap.Uint64("nonce", repl.Nonce),
+			zap.String("tip_cap", repl.GasTipCap.String()),
+			zap.String("fee_cap", repl.GasFeeCap.String()),
+		)
+	}
+}
+
+// dropUnknown forgets a transaction the node no longer knows, unless an
+// earlier version of it mined. Its nonce is reused through the nonce gap
+// check and its ids become eligible again: batch ids for the next batch,
+// approve ids for another approval attempt.
+func (w *Watcher) dropUnknown(ctx context.Context, ethClient txReplacer, tx store.TxRecord) {
+	receipt, err := w.minedReceipt(ctx, ethClient, tx)
+	if err != nil {
+		w.log.Error("receipt fetch failed", zap.String("tx", tx.Hash.Hex()), zap.Error(err))
+		w.metrics.IncFailures()
+		return
+	}
+	if receipt != nil {
+		return
+	}
+	w.state.DeleteTx(tx.Hash)
+	w.log.Warn("tx dropped from mempool", zap.String("kind", string(tx.Kind)), zap.String("tx", tx.Hash.Hex()), zap.Uint64s("ids", tx.IDs))
+	for _, id := range tx.IDs {
+		if tx.Kind == store.TxApprove {
+			w.reapprove(id, "transaction dropped")
+			continue
+		}
+		w.requeue(id, "transaction dropped")
+	}
+}
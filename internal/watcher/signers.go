@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"base-treasury-guard/internal/client"
+
+	"go.uber.org/zap"
+)
+
+var ErrSignerCheck = errors.New("signer check failed")
+
+type signerChecker interface {
+	SignerStatus(ctx context.Context) ([]client.SignerStatus, error)
+}
+
+// signerProblems explains, per role, why its signer cannot send: no key, a
+// missing role on the contract or too little ETH for gas.
+func signerProblems(statuses []client.SignerStatus, minBalance *big.Int) map[string]string {
+	problems := make(map[string]string)
+	for _, s := range statuses {
+		switch {
+		case !s.Configured:
+			problems[s.Role] = "no signer configured"
+		case !s.HasRole:
+			problems[s.Role] = fmt.Sprintf("%s does not hold %s on the contract", s.Address.Hex(), client.RoleName(s.RoleHash))
+		case minBalance != nil && s.Balance != nil && s.Balance.Cmp(minBalance) < 0:
+			problems[s.Role] = fmt.Sprintf("%s has %s wei, below MIN_SIGNER_BALANCE %s", s.Address.Hex(), s.Balance, minBalance)
+		}
+	}
+	return problems
+}
+
+// verifySigners runs the startup signer checks. Failures stop the daemon
+// unless SIGNER_CHECK_ACTION=readonly, in which case the affected roles are
+// switched off and everything else keeps running.
+func (w *Watcher) verifySigners(ctx context.Context, ethClient signerChecker) error {
+	statuses, err := ethClient.SignerStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignerCheck, err)
+	}
+	problems := signerProblems(statuses, w.minBalance)
+	for _, s := range statuses {
+		if reason, ok := problems[s.Role]; ok {
+			w.log.Error("signer check failed", zap.String("role", s.Role), zap.String("reason", reason))
+			continue
+		}
+		w.log.Info("signer verified", zap.String("role", s.Role), zap.String("address", s.Address.Hex()), zap.String("balance", s.Balance.String()))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if w.cfg.SignerCheckAction != "readonly" {
+		reasons := make([]string, 0, len(problems))
+		for _, s := range statuses {
+			if reason, ok := problems[s.Role]; ok {
+				reasons = append(reasons, s.Role+": "+reason)
+			}
+		}
+		return fmt.Errorf("%w: %s (set SIGNER_CHECK_ACTION=readonly to start without these roles)", ErrSignerCheck, strings.Join(reasons, "; "))
+	}
+	w.approveBlocked = problems["guardian"]
+	w.executeBlocked = problems["executor"]
+	w.log.Warn("starting read-only for roles that failed the signer check",
+		zap.Bool("approvals", w.approveBlocked == ""),
+		zap.Bool("executions", w.executeBlocked == ""),
+	)
+	return nil
+}
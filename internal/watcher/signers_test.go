@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/config"
+	"base-treasury-guard/internal/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type fakeSigners []client.SignerStatus
+
+func (f fakeSigners) SignerStatus(ctx context.Context) ([]client.SignerStatus, error) {
+	return f, nil
+}
+
+func TestVerifySigners(t *testing.T) {
+	guardian := client.SignerStatus{Role: "guardian", RoleHash: client.GuardianRole, Configured: true, Address: common.HexToAddress("0x01"), HasRole: true, Balance: big.NewInt(5e15)}
+	executor := client.SignerStatus{Role: "executor", RoleHash: client.ExecutorRole, Configured: true, Address: common.HexToAddress("0x02"), HasRole: false, Balance: big.NewInt(5e15)}
+	cfg := config.Config{MinSignerBalance: "1000000000000000"}
+
+	w := New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
+	if err := w.verifySigners(context.Background(), fakeSigners{guardian, executor}); !errors.Is(err, ErrSignerCheck) || !strings.Contains(err.Error(), "EXECUTOR_ROLE") {
+		t.Fatalf("expected refusal naming the missing role, got %v", err)
+	}
+
+	cfg.SignerCheckAction = "readonly"
+	w = New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
+	if err := w.verifySigners(context.Background(), fakeSigners{guardian, executor}); err != nil {
+		t.Fatalf("readonly: %v", err)
+	}
+	if w.approveBlocked != "" || w.executeBlocked == "" {
+		t.Fatalf("expected only executions blocked, got approve %q execute %q", w.approveBlocked, w.executeBlocked)
+	}
+
+	poor := guardian
+	poor.Balance = big.NewInt(1)
+	unset := client.SignerStatus{Role: "executor", RoleHash: client.ExecutorRole}
+	problems := signerProblems([]client.SignerStatus{poor, unset}, w.minBalance)
+	if !strings.Contains(problems["guardian"], "MIN_SIGNER_BALANCE") || problems["executor"] != "no signer configured" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
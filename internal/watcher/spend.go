@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+const (
+	scopeToken     = "token"
+	scopeRecipient = "recipient"
+	scopeCreator   = "creator"
+)
+
+// spendLimit caps the sum of amounts for one token within a rolling window,
+// optionally narrowed to the request's recipient or creator.
+type spendLimit struct {
+	window string
+	span   time.Duration
+	scope  string
+	max    policyAmount
+}
+
+func (l spendLimit) matches(rec store.SpendRecord, req PolicyRequest) bool {
+	switch l.scope {
+	case scopeRecipient:
+		return rec.To == req.To
+	case scopeCreator:
+		return rec.CreatedBy == req.CreatedBy
+	default:
+		return true
+	}
+}
+
+type spendLimitSource interface {
+	spendLimits(token common.Address) []spendLimit
+}
+
+// SpendStore persists the spend ledger across restarts.
+type SpendStore interface {
+	Spends() []store.SpendRecord
+	PutSpend(rec store.SpendRecord)
+	DeleteSpend(id uint64)
+}
+
+// SpendLimitRule enforces rolling daily and weekly caps. Every approval
+// guardd sends is recorded, as is any tracked request executed on chain,
+// so a cap added later already sees the recent history. Cancelled and
+// expired requests are released.
+type SpendLimitRule struct {
+	limits spendLimitSource
+	now    func() time.Time
+
+	mu     sync.Mutex
+	spends map[uint64]store.SpendRecord
+	store  SpendStore
+}
+
+func NewSpendLimitRule(limits spendLimitSource, now func() time.Time) *SpendLimitRule {
+	if now == nil {
+		now = time.Now
+	}
+	return &SpendLimitRule{limits: limits, now: now, spends: make(map[uint64]store.SpendRecord)}
+}
+
+func (r *SpendLimitRule) Name() string { return "spend_limit" }
+
+// UseStore loads the ledger from s and writes every later change through.
+func (r *SpendLimitRule) UseStore(s SpendStore) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.store = s
+	for _, rec := range s.Spends() {
+		if _, ok := r.spends[rec.ID]; !ok {
+			r.spends[rec.ID] = rec
+		}
+	}
+	r.prune()
+}
+
+func (r *SpendLimitRule) Evaluate(req PolicyRequest) Decision {
+	limits := r.limits.spendLimits(req.Token)
+	if len(limits) == 0 {
+		return allow()
+	}
+	amount := req.Amount
+	if amount == nil {
+		amount = new(big.Int)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+	now := r.now()
+	for _, l := range limits {
+		max, err := l.max.resolve(req)
+		if err != nil {
+			return limitDenial(r.Name(), err)
+		}
+		used := new(big.Int)
+		for id, rec := range r.spends {
+			if id == req.ID || rec.Token != req.Token || now.Sub(rec.At) >= l.span || !l.matches(rec, req) {
+				continue
+			}
+			used.Add(used, rec.Amount)
+		}
+		if total := new(big.Int).Add(used, amount); total.Cmp(max) > 0 {
+			return deny(r.Name(), ReasonSpendLimitExceeded, fmt.Sprintf("%s %s limit: %s of %s used, request adds %s", l.window, l.scope, formatAmount(used, req.Asset), formatAmount(max, req.Asset), formatAmount(amount, req.Asset)))
+		}
+	}
+	return allow()
+}
+
+func (r *SpendLimitRule) Record(req PolicyRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.put(store.SpendRecord{ID: req.ID, Token: req.Token, To: req.To, CreatedBy: req.CreatedBy, Amount: req.Amount, At: r.now()})
+}
+
+// executed counts a request that executed without having been approved
+// through this rule.
+func (r *SpendLimitRule) executed(req client.RequestState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.spends[req.ID]; ok {
+		return
+	}
+	r.put(store.SpendRecord{ID: req.ID, Token: req.Token, To: req.To, CreatedBy: req.CreatedBy, Amount: req.Amount, At: r.now()})
+}
+
+// release drops a request that will never pay out.
+func (r *SpendLimitRule) release(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(id)
+}
+
+func (r *SpendLimitRule) put(rec store.SpendRecord) {
+	if rec.Amount == nil {
+		rec.Amount = new(big.Int)
+	}
+	r.spends[rec.ID] = rec
+	if r.store != nil {
+		r.store.PutSpend(rec)
+	}
+}
+
+func (r *SpendLimitRule) remove(id uint64) {
+	if _, ok := r.spends[id]; !ok {
+		return
+	}
+	delete(r.spends, id)
+	if r.store != nil {
+		r.store.DeleteSpend(id)
+	}
+}
+
+// prune forgets records older than the longest window.
+func (r *SpendLimitRule) prune() {
+	now := r.now()
+	for id, rec := range r.spends {
+		if now.Sub(rec.At) >= week {
+			r.remove(id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"base-treasury-guard/internal/client"
+	"base-treasury-guard/internal/config"
+	"base-treasury-guard/internal/metrics"
+	"base-treasury-guard/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+type fixedLimits []spendLimit
+
+func (l fixedLimits) spendLimits(common.Address) []spendLimit { return l }
+
+func TestSpendLimitsRollAndSurviveRestart(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	alice := common.HexToAddress("0x6666666666666666666666666666666666666666")
+	bob := common.HexToAddress("0x7777777777777777777777777777777777777777")
+	limits := fixedLimits{
+		{window: "daily", span: day, scope: scopeRecipient, max: policyAmount{raw: big.NewInt(100)}},
+		{window: "weekly", span: week, scope: scopeToken, max: policyAmount{raw: big.NewInt(250)}},
+	}
+
+	state := store.NewMemory()
+	rule := NewSpendLimitRule(limits, clock)
+	rule.UseStore(state)
+	chain := NewPolicyChain(rule)
+
+	if d := chain.Evaluate(PolicyRequest{ID: 1, To: alice, Amount: big.NewInt(60)}); !d.Allow {
+		t.Fatalf("expected first spend to pass, got %+v", d)
+	}
+	d := chain.Evaluate(PolicyRequest{ID: 2, To: alice, Amount: big.NewInt(50)})
+	if d.Allow || d.Reason != ReasonSpendLimitExceeded || !strings.Contains(d.Detail, "60 of 100 used") {
+		t.Fatalf("expected daily recipient denial, got %+v", d)
+	}
+	if d := chain.Evaluate(PolicyRequest{ID: 1, To: alice, Amount: big.NewInt(60)}); !d.Allow {
+		t.Fatalf("expected re-evaluating an approved request not to count it twice, got %+v", d)
+	}
+	if d := chain.Evaluate(PolicyRequest{ID: 3, To: bob, Amount: big.NewInt(90)}); !d.Allow {
+		t.Fatalf("expected other recipient to pass, got %+v", d)
+	}
+
+	now = now.Add(25 * time.Hour)
+	restarted := NewSpendLimitRule(limits, clock)
+	restarted.UseStore(state)
+	restarted.executed(client.RequestState{ID: 4, To: bob, Amount: big.NewInt(80)})
+
+	if d := restarted.Evaluate(PolicyRequest{ID: 5, To: alice, Amount: big.NewInt(20)}); !d.Allow {
+		t.Fatalf("expected daily window to roll over, got %+v", d)
+	}
+	d = restarted.Evaluate(PolicyRequest{ID: 5, To: alice, Amount: big.NewInt(30)})
+	if d.Allow || !strings.Contains(d.Detail, "weekly token limit: 230 of 250 used") {
+		t.Fatalf("expected weekly denial from persisted spends, got %+v", d)
+	}
+
+	restarted.release(4)
+	if d := restarted.Evaluate(PolicyRequest{ID: 5, To: alice, Amount: big.NewInt(30)}); !d.Allow {
+		t.Fatalf("expected released request to free the window, got %+v", d)
+	}
+	if len(state.Spends()) != 2 {
+		t.Fatalf("expected store to track spends, got %+v", state.Spends())
+	}
+}
+
+func TestLapsedRequestReleasesSpend(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.spendLimits = NewSpendLimitRule(fixedLimits{
+		{window: "daily", span: day, scope: scopeToken, max: policyAmount{raw: big.NewInt(100)}},
+	}, nil)
+	w.spendLimits.UseStore(w.state)
+
+	w.spendLimits.Record(PolicyRequest{ID: 1, Amount: big.NewInt(60)})
+	w.requests.track(client.RequestState{ID: 1, Amount: big.NewInt(60), ExpiresAt: 100})
+	if d := w.spendLimits.Evaluate(PolicyRequest{ID: 2, Amount: big.NewInt(50)}); d.Allow {
+		t.Fatalf("expected approved request to hold its budget")
+	}
+
+	if batch := w.buildReadyBatch(context.Background(), &fakeClient{now: 200}); len(batch) != 0 {
+		t.Fatalf("expected lapsed request to stay out of the batch, got %v", batch)
+	}
+	if w.requests.has(1) {
+		t.Fatalf("expected lapsed request to be forgotten")
+	}
+	if d := w.spendLimits.Evaluate(PolicyRequest{ID: 2, Amount: big.NewInt(50)}); !d.Allow {
+		t.Fatalf("expected lapsed request to free its budget, got %+v", d)
+	}
+	if len(w.state.Spends()) != 0 {
+		t.Fatalf("expected spend record deleted, got %+v", w.state.Spends())
+	}
+}
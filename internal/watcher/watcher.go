@@ -2,12 +2,16 @@ package watcher
 
 import (
 	"context"
+	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 	"time"
 
 	"base-treasury-guard/internal/client"
 	"base-treasury-guard/internal/config"
 	"base-treasury-guard/internal/metrics"
+	"base-treasury-guard/internal/store"
 
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
@@ -17,9 +21,46 @@ type Watcher struct {
 	cfg               config.Config
 	log               *zap.Logger
 	metrics           *metrics.Registry
-	allowedTokens     map[common.Address]struct{}
-	maxAmount         *big.Int
+	policy            PolicyEngine
+	policyFile        *PolicyFileRule
+	spendLimits       *SpendLimitRule
+	assets            map[common.Address]client.TokenInfo
+	prices            map[common.Address]priceCheck
+	recipients        *RecipientRule
+	recipientCode     map[common.Address]bool
+	minBalance        *big.Int
 	execCooldownUntil map[uint64]time.Time
+	approveRetry      map[uint64]time.Time
+	held              map[uint64]heldRequest
+	deferred          *deferQueue
+	state             *store.Store
+	requests          *requestBook
+	unconfirmed       map[uint64]client.EventMeta
+	confirmedBlock    uint64
+	head              uint64
+	lastReconcile     time.Time
+
+	// approveBlocked and executeBlocked hold why a role may not send; empty
+	// means it can.
+	approveBlocked string
+	executeBlocked string
+	intents        *intentLog
+	lastExecIntent string
+}
+
+const execCooldown = 30 * time.Second
+
+// maxHoldBackoff caps the wait between policy re-evaluations of a held
+// request.
+const maxHoldBackoff = 10 * time.Minute
+
+// heldRequest is a request whose policy evaluation is retried on a later
+// tick: one refused because a lookup the policy needs failed, re-evaluated
+// with exponential backoff, or one restored from a checkpoint before its
+// approval was sent.
+type heldRequest struct {
+	until    time.Time
+	attempts int
 }
 
 type requestClient interface {
@@ -27,31 +68,96 @@ type requestClient interface {
 	GetRequest(ctx context.Context, id uint64) (client.RequestState, error)
 }
 
+type tokenInfoSource interface {
+	TokenInfo(ctx context.Context, token common.Address) (client.TokenInfo, error)
+}
+
+type priceSource interface {
+	LatestPrice(ctx context.Context, feed common.Address) (client.PriceQuote, error)
+}
+
+// priceCheck is the outcome of the last USD price lookup for a token.
+type priceCheck struct {
+	quote *client.PriceQuote
+	err   error
+}
+
+type codeSource interface {
+	HasCode(ctx context.Context, account common.Address) (bool, error)
+}
+
+type screenSource interface {
+	tokenInfoSource
+	priceSource
+	codeSource
+}
+
+type headSource interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+type chainHeads interface {
+	ConfirmedBlock(ctx context.Context) (uint64, error)
+	CanonicalHash(ctx context.Context, number uint64) (common.Hash, error)
+}
+
 func New(cfg config.Config, log *zap.Logger, metrics *metrics.Registry) *Watcher {
 	if log == nil {
 		log = zap.NewNop()
 	}
-	w := &Watcher{cfg: cfg, log: log, metrics: metrics, execCooldownUntil: make(map[uint64]time.Time)}
-	w.allowedTokens = make(map[common.Address]struct{})
-	for _, token := range cfg.PolicyAllowedTokens {
-		if common.IsHexAddress(token) {
-			w.allowedTokens[common.HexToAddress(token)] = struct{}{}
-		}
+	w := &Watcher{cfg: cfg, log: log, metrics: metrics, execCooldownUntil: make(map[uint64]time.Time), approveRetry: make(map[uint64]time.Time), held: make(map[uint64]heldRequest), deferred: newDeferQueue(), intents: &intentLog{}, state: store.NewMemory(), requests: newRequestBook(), unconfirmed: make(map[uint64]client.EventMeta), assets: make(map[common.Address]client.TokenInfo), prices: make(map[common.Address]priceCheck), recipientCode: make(map[common.Address]bool)}
+	var maxAmount *big.Int
+	if amt, ok := new(big.Int).SetString(cfg.PolicyMaxAmount, 10); ok && amt.Sign() > 0 {
+		maxAmount = amt
 	}
-	if cfg.PolicyMaxAmount != "" && cfg.PolicyMaxAmount != "0" {
-		if amt, ok := new(big.Int).SetString(cfg.PolicyMaxAmount, 10); ok {
-			w.maxAmount = amt
+	w.recipients = NewRecipientRule(cfg.RecipientDenylistFile, cfg.RecipientCodeCheck, cfg.RecipientAllowedContracts)
+	for _, self := range []struct{ addr, label string }{
+		{cfg.ContractAddress, "TreasuryGuard contract"},
+		{cfg.GuardianAddress, "guardian signer"},
+		{cfg.ExecutorAddress, "executor signer"},
+	} {
+		if common.IsHexAddress(self.addr) {
+			w.recipients.Protect(common.HexToAddress(self.addr), self.label)
 		}
 	}
+	rules := []PolicyRule{NewAllowlistRule(cfg.PolicyAllowedTokens), MaxAmountRule{Max: maxAmount}, w.recipients}
+	if cfg.PolicyFile != "" {
+		w.policyFile = NewPolicyFileRule(cfg.PolicyFile)
+		w.spendLimits = NewSpendLimitRule(w.policyFile, nil)
+		rules = append(rules, w.policyFile, w.spendLimits)
+	}
+	w.policy = NewPolicyChain(append(rules, NewCooldownRule(cfg.PolicyCooldown, nil))...)
+	if amt, ok := new(big.Int).SetString(cfg.MinSignerBalance, 10); ok && amt.Sign() > 0 {
+		w.minBalance = amt
+	}
 	return w
 }
 
 func (w *Watcher) Run(ctx context.Context) error {
+	if w.policyFile != nil {
+		if _, err := w.policyFile.Reload(); err != nil {
+			w.log.Error("invalid policy file", zap.String("path", w.cfg.PolicyFile), zap.Error(err))
+			return err
+		}
+		w.policyLoaded()
+	}
+	_, size, err := w.recipients.Reload()
+	if err != nil {
+		w.log.Error("invalid recipient denylist", zap.String("path", w.cfg.RecipientDenylistFile), zap.Error(err))
+		return err
+	}
+	if w.cfg.RecipientDenylistFile != "" {
+		w.denylistLoaded(size)
+	}
+
 	ethClient, err := client.New(w.cfg, w.log)
 	if err != nil {
 		return err
 	}
 	defer ethClient.Close()
+	for _, addr := range ethClient.SignerAddresses() {
+		w.recipients.Protect(addr, "guardd signer")
+	}
 
 	chainID, err := ethClient.CheckChainID(ctx, w.cfg.ChainID)
 	if err != nil {
@@ -60,14 +166,70 @@ func (w *Watcher) Run(ctx context.Context) error {
 	}
 	w.log.Info("connected", zap.Uint64("chain_id", chainID), zap.String("contract", w.cfg.ContractAddress))
 
-	events, errs := ethClient.SubscribeRequestCreated(ctx)
-	active := make(map[uint64]struct{})
+	switch w.cfg.Mode {
+	case config.ModeObserver:
+		w.approveBlocked, w.executeBlocked = "observer mode", "observer mode"
+		w.log.Info("observer mode, approvals and executions are computed but never sent")
+	case config.ModeDryRun:
+		w.log.Info("dry-run mode, approvals and executions are simulated but never sent")
+		if err := w.verifySigners(ctx, ethClient); err != nil {
+			w.log.Error("refusing to start", zap.Error(err))
+			return err
+		}
+	case config.ModeActive, "":
+		if err := w.verifySigners(ctx, ethClient); err != nil {
+			w.log.Error("refusing to start", zap.Error(err))
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown GUARDD_MODE %q", w.cfg.Mode)
+	}
+
+	state, err := w.openState()
+	if err != nil {
+		return err
+	}
+	w.state = state
+	ethClient.UseNonceStore(state)
+	if w.spendLimits != nil {
+		w.spendLimits.UseStore(state)
+	}
+	w.restore()
+	for _, id := range w.requests.ids() {
+		if req, ok := w.requests.get(id); ok {
+			w.lookupToken(ctx, ethClient, req.Token)
+		}
+	}
+
+	startBlock := w.cfg.StartBlock
+	if last := w.state.LastBlock(); last > 0 {
+		startBlock = last + 1
+		w.log.Info("resuming from checkpoint", zap.String("path", w.state.Path()), zap.Uint64("last_block", last), zap.Int("active", w.requests.len()))
+	} else if startBlock == 0 {
+		startBlock, err = ethClient.DeploymentBlock(ctx)
+		if err != nil {
+			head, headErr := ethClient.BlockNumber(ctx)
+			if headErr != nil {
+				return headErr
+			}
+			w.log.Warn("deployment block lookup failed, backfill skipped", zap.Error(err), zap.Uint64("head", head))
+			startBlock = head
+		}
+	}
+	w.log.Info("backfill starting", zap.Uint64("from_block", startBlock))
+
+	if err := w.trackHead(ctx, ethClient); err != nil {
+		return err
+	}
+	events, errs := ethClient.SubscribeEvents(ctx, startBlock)
 	ticker := time.NewTicker(w.cfg.PollInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			w.advanceCheckpoint(ethClient.SyncedBlock())
+			w.checkpoint()
 			w.log.Info("watcher stopped")
 			return nil
 		case err := <-errs:
@@ -78,86 +240,617 @@ func (w *Watcher) Run(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
-			if evt.ID == nil || !evt.ID.IsUint64() {
-				continue
+			w.handleEvent(ctx, ethClient, evt)
+			w.checkpoint()
+		case <-ticker.C:
+			w.reloadPolicy()
+			w.trackHead(ctx, ethClient)
+			for _, h := range ethClient.EndpointHealth() {
+				w.metrics.SetEndpointHealth(h.Name, h.Latency, h.ErrorRate, h.Breaker == "open")
 			}
-			id := evt.ID.Uint64()
-			active[id] = struct{}{}
-
-			req, err := ethClient.GetRequest(ctx, id)
-			if err != nil {
-				w.log.Error("request fetch failed", zap.Error(err))
-				w.metrics.IncFailures()
+			for _, id := range w.confirmCreations(ctx, ethClient) {
+				w.handleCreated(ctx, ethClient, id)
+			}
+			w.trackReceipts(ctx, ethClient)
+			w.replaceStuck(ctx, ethClient, w.head)
+			w.checkNonces(ctx, ethClient)
+			w.retryApprovals(ctx, ethClient)
+			w.retryHeld(ctx, ethClient)
+			w.advanceCheckpoint(ethClient.SyncedBlock())
+			if time.Since(w.lastReconcile) >= w.cfg.ReconcileInterval {
+				w.reconcile(ctx, ethClient)
+			}
+			batch := w.buildReadyBatch(ctx, ethClient)
+			if len(batch) == 0 {
+				w.checkpoint()
 				continue
 			}
-			if !w.policyAllows(req) {
-				w.log.Info("request rejected",
-					zap.Uint64("id", id),
-					zap.String("token", req.Token.Hex()),
-					zap.String("amount", req.Amount.String()),
-				)
+			if w.executeBlocked != "" {
+				w.recordIntent(Intent{Action: "execute", IDs: batch, Reason: w.executeBlocked})
+				w.checkpoint()
 				continue
 			}
-
-			hash, err := ethClient.Approve(ctx, id)
+			res, err := ethClient.ExecuteBatch(ctx, batch, w.cfg.GasFloor)
 			if err != nil {
-				w.metrics.IncFailures()
-				w.log.Error("approve failed", zap.Uint64("id", id), zap.Error(err))
+				if actionFor(err) != actionDefer {
+					w.metrics.IncFailures()
+				}
+				w.batchFailed(ctx, ethClient, batch, err, "execute batch failed")
+				w.checkpoint()
 				continue
 			}
-			w.metrics.IncApprovals()
-			w.log.Info("approve sent", zap.Uint64("id", id), zap.String("tx", hash.Hex()))
-		case <-ticker.C:
-			batch := w.buildReadyBatch(ctx, ethClient, active)
-			if len(batch) == 0 {
+			if res.Skipped {
+				w.metrics.IncSimulationSkips()
+				w.batchFailed(ctx, ethClient, batch, res.Cause, "execute batch skipped, simulation reverted")
+				w.checkpoint()
 				continue
 			}
-			hash, err := ethClient.ExecuteBatch(ctx, batch, w.cfg.GasFloor)
-			if err != nil {
-				w.metrics.IncFailures()
-				w.log.Error("execute batch failed", zap.Error(err))
+			if res.DryRun {
+				w.recordIntent(dryRunIntent("execute", batch, res))
+				for _, id := range batch {
+					w.execCooldownUntil[id] = time.Now().Add(execCooldown)
+				}
+				w.checkpoint()
 				continue
 			}
+			hash := res.Hash
+			sentAt := time.Now()
 			for _, id := range batch {
-				w.execCooldownUntil[id] = time.Now().Add(30 * time.Second)
+				w.execCooldownUntil[id] = sentAt.Add(execCooldown)
 			}
-			w.metrics.IncExecutions()
+			w.state.PutTx(store.TxRecord{Hash: hash, Kind: store.TxExecute, IDs: batch, SentAt: sentAt, SentBlock: w.head})
+			w.resume(store.TxExecute, batch)
+			w.checkpoint()
 			w.log.Info("execute batch sent", zap.Int("count", len(batch)), zap.String("tx", hash.Hex()))
 		}
 	}
 }
 
-func (w *Watcher) buildReadyBatch(ctx context.Context, ethClient requestClient, active map[uint64]struct{}) []uint64 {
-	now, err := ethClient.ChainTime(ctx)
+func (w *Watcher) handleEvent(ctx context.Context, ethClient *client.EthClient, evt client.Event) {
+	meta := evt.Metadata()
+	switch e := evt.(type) {
+	case client.RequestCreatedEvent:
+		if e.ID == nil || !e.ID.IsUint64() {
+			return
+		}
+		w.handleCreatedLog(e.ID.Uint64(), meta)
+	case client.RequestApprovedEvent, client.RequestCancelledEvent, client.RequestExpiredEvent,
+		client.RequestExecutedEvent, client.BatchExecutedEvent:
+		if meta.Removed {
+			w.metrics.IncReorgs()
+			for _, id := range lifecycleIDs(evt) {
+				w.log.Warn("lifecycle event reorged out", zap.String("event", evt.EventName()), zap.Uint64("id", id), zap.Uint64("block", meta.BlockNumber))
+				w.refresh(ctx, ethClient, id)
+			}
+			return
+		}
+		for _, req := range w.requests.apply(evt) {
+			w.updateRequest(req)
+		}
+	case client.PausedEvent:
+		w.log.Warn("contract paused", zap.String("account", e.Account.Hex()), zap.Uint64("block", meta.BlockNumber))
+	case client.UnpausedEvent:
+		w.log.Info("contract unpaused", zap.String("account", e.Account.Hex()), zap.Uint64("block", meta.BlockNumber))
+	case client.RoleGrantedEvent:
+		w.log.Info("role granted", zap.String("role", client.RoleName(e.Role)), zap.String("account", e.Account.Hex()))
+	case client.RoleRevokedEvent:
+		w.log.Warn("role revoked", zap.String("role", client.RoleName(e.Role)), zap.String("account", e.Account.Hex()))
+	default:
+		w.log.Debug("event", zap.String("name", evt.EventName()), zap.Uint64("block", meta.BlockNumber), zap.String("tx", meta.TxHash.Hex()))
+	}
+}
+
+// handleCreatedLog queues a new request until its creation block is confirmed,
+// or rolls it back when the log was removed by a reorg.
+func (w *Watcher) handleCreatedLog(id uint64, meta client.EventMeta) {
+	if !meta.Removed {
+		if !w.requests.has(id) {
+			w.unconfirmed[id] = meta
+		}
+		return
+	}
+	w.metrics.IncReorgs()
+	if pending, ok := w.unconfirmed[id]; ok && pending.BlockHash == meta.BlockHash {
+		delete(w.unconfirmed, id)
+		w.log.Warn("request creation reorged out", zap.Uint64("id", id), zap.Uint64("block", meta.BlockNumber))
+		return
+	}
+	if w.requests.has(id) {
+		w.forget(id)
+		w.log.Error("confirmed request creation reorged out", zap.Uint64("id", id), zap.Uint64("block", meta.BlockNumber))
+	}
+}
+
+// confirmCreations returns the queued requests whose creation block is now
+// at or below the confirmed block and still canonical. Entries whose block
+// hash changed were reorged out and are dropped.
+func (w *Watcher) confirmCreations(ctx context.Context, heads chainHeads) []uint64 {
+	confirmed, err := heads.ConfirmedBlock(ctx)
 	if err != nil {
-		w.log.Error("chain time fetch failed", zap.Error(err))
+		w.log.Error("confirmed block fetch failed", zap.Error(err))
 		w.metrics.IncFailures()
 		return nil
 	}
-	batch := make([]uint64, 0, w.cfg.MaxBatch)
-	for id := range active {
+	w.confirmedBlock = confirmed
+
+	ids := make([]uint64, 0, len(w.unconfirmed))
+	for id := range w.unconfirmed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var ready []uint64
+	for _, id := range ids {
+		meta := w.unconfirmed[id]
+		if meta.BlockNumber > confirmed {
+			continue
+		}
+		hash, err := heads.CanonicalHash(ctx, meta.BlockNumber)
+		if err != nil {
+			w.log.Error("block hash fetch failed", zap.Uint64("block", meta.BlockNumber), zap.Error(err))
+			w.metrics.IncFailures()
+			continue
+		}
+		delete(w.unconfirmed, id)
+		if hash != meta.BlockHash {
+			w.metrics.IncReorgs()
+			w.log.Warn("request creation reorged out", zap.Uint64("id", id), zap.Uint64("block", meta.BlockNumber))
+			continue
+		}
+		ready = append(ready, id)
+	}
+	return ready
+}
+
+// trackHead records the latest chain head. Fee bump windows are counted
+// against it rather than the log sync position, which only advances when the
+// contract emits events.
+func (w *Watcher) trackHead(ctx context.Context, heads headSource) error {
+	head, err := heads.BlockNumber(ctx)
+	if err != nil {
+		w.log.Error("head fetch failed", zap.Error(err))
+		w.metrics.IncFailures()
+		return err
+	}
+	if head > w.head {
+		w.head = head
+	}
+	return nil
+}
+
+// advanceCheckpoint moves the resume block forward, but never past the
+// confirmed block or a creation that is still waiting for confirmations, so a
+// restart replays anything a reorg could still change.
+func (w *Watcher) advanceCheckpoint(synced uint64) {
+	last := synced
+	if w.confirmedBlock < last {
+		last = w.confirmedBlock
+	}
+	for _, meta := range w.unconfirmed {
+		if meta.BlockNumber == 0 {
+			return
+		}
+		if meta.BlockNumber-1 < last {
+			last = meta.BlockNumber - 1
+		}
+	}
+	w.state.SetLastBlock(last)
+}
+
+// refresh re-reads a request after one of its events was reorged out, which
+// can bring a finalized request back to pending. A pending request without
+// a sent approval is evaluated again.
+func (w *Watcher) refresh(ctx context.Context, ethClient requestClient, id uint64) {
+	req, err := ethClient.GetRequest(ctx, id)
+	if err != nil {
+		w.log.Error("request fetch failed", zap.Uint64("id", id), zap.Error(err))
+		w.metrics.IncFailures()
+		return
+	}
+	if req.Status != statusPending && !w.requests.has(id) {
+		return
+	}
+	w.updateRequest(req)
+	if req.Status == statusPending {
+		w.reevaluate(id)
+	}
+}
+
+// reevaluate queues a tracked request for a policy evaluation and approval
+// on the next tick, unless its approval was already sent.
+func (w *Watcher) reevaluate(id uint64) {
+	if w.state.HasTx(store.TxApprove, id) {
+		return
+	}
+	if _, ok := w.held[id]; !ok {
+		w.held[id] = heldRequest{}
+	}
+}
+
+func lifecycleIDs(evt client.Event) []uint64 {
+	var ids []*big.Int
+	switch e := evt.(type) {
+	case client.RequestApprovedEvent:
+		ids = []*big.Int{e.ID}
+	case client.RequestCancelledEvent:
+		ids = []*big.Int{e.ID}
+	case client.RequestExpiredEvent:
+		ids = []*big.Int{e.ID}
+	case client.RequestExecutedEvent:
+		ids = []*big.Int{e.ID}
+	case client.BatchExecutedEvent:
+		ids = e.IDsProcessed
+	}
+	out := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id != nil && id.IsUint64() {
+			out = append(out, id.Uint64())
+		}
+	}
+	return out
+}
+
+func (w *Watcher) handleCreated(ctx context.Context, ethClient *client.EthClient, id uint64) {
+	if w.requests.has(id) {
+		return
+	}
+	req, err := ethClient.GetRequest(ctx, id)
+	if err != nil {
+		w.log.Error("request fetch failed", zap.Error(err))
+		w.metrics.IncFailures()
+		return
+	}
+	if req.Status != statusPending {
+		w.log.Debug("request already finalized", zap.Uint64("id", id), zap.Uint8("status", req.Status))
+		return
+	}
+	w.updateRequest(req)
+	w.admit(ctx, ethClient, req)
+}
+
+// admit approves req if the policy allows it.
+func (w *Watcher) admit(ctx context.Context, ethClient *client.EthClient, req client.RequestState) {
+	if w.screen(ctx, ethClient, req) {
+		w.approve(ctx, ethClient, req.ID)
+	}
+}
+
+// screen refreshes the lookups the policy needs for req and evaluates it.
+// A denial caused by a failed lookup holds the request for another attempt
+// on a later tick; any other denial is final.
+func (w *Watcher) screen(ctx context.Context, src screenSource, req client.RequestState) bool {
+	w.lookupToken(ctx, src, req.Token)
+	w.lookupPrice(ctx, src, req.Token)
+	w.lookupRecipient(ctx, src, req.To)
+	d := w.evaluatePolicy(req)
+	if d.Allow {
+		delete(w.held, req.ID)
+		return true
+	}
+	fields := append([]zap.Field{zap.Uint64("id", req.ID)}, w.amountFields(req)...)
+	fields = append(fields, zap.String("rule", d.Rule), zap.String("reason", d.Reason), zap.String("detail", d.Detail))
+	if !d.Retryable() {
+		delete(w.held, req.ID)
+		w.log.Info("request rejected", fields...)
+		return false
+	}
+	hold := w.held[req.ID]
+	delay := execCooldown << hold.attempts
+	if hold.attempts >= 5 || delay > maxHoldBackoff {
+		delay = maxHoldBackoff
+	}
+	hold.attempts++
+	hold.until = time.Now().Add(delay)
+	w.held[req.ID] = hold
+	w.log.Warn("request held, policy input unavailable", append(fields, zap.Int("attempt", hold.attempts), zap.Duration("retry_in", delay))...)
+	return false
+}
+
+// retryHeld re-evaluates held requests whose backoff has passed.
+func (w *Watcher) retryHeld(ctx context.Context, ethClient *client.EthClient) {
+	now := time.Now()
+	for id, hold := range w.held {
+		if now.Before(hold.until) {
+			continue
+		}
+		req, ok := w.requests.get(id)
+		if !ok {
+			delete(w.held, id)
+			continue
+		}
+		w.admit(ctx, ethClient, req)
+	}
+}
+
+func (w *Watcher) approve(ctx context.Context, ethClient *client.EthClient, id uint64) {
+	if w.approveBlocked != "" {
+		w.recordIntent(Intent{Action: "approve", IDs: []uint64{id}, Reason: w.approveBlocked})
+		return
+	}
+	if w.state.HasTx(store.TxApprove, id) {
+		w.log.Info("approve already sent", zap.Uint64("id", id))
+		return
+	}
+	approved, err := ethClient.HasApproved(ctx, id)
+	if err != nil {
+		w.log.Error("approval lookup failed", zap.Uint64("id", id), zap.Error(err))
+		w.metrics.IncFailures()
+		w.approveRetry[id] = time.Now().Add(execCooldown)
+		return
+	}
+	if approved {
+		w.log.Info("request already approved", zap.Uint64("id", id))
+		return
+	}
+
+	res, err := ethClient.Approve(ctx, id)
+	if err != nil {
+		if actionFor(err) != actionDefer {
+			w.metrics.IncFailures()
+		}
+		w.approveFailed(ctx, ethClient, id, err, "approve failed")
+		return
+	}
+	if res.Skipped {
+		w.metrics.IncSimulationSkips()
+		w.approveFailed(ctx, ethClient, id, res.Cause, "approve skipped, simulation reverted")
+		return
+	}
+	if res.DryRun {
+		w.recordIntent(dryRunIntent("approve", []uint64{id}, res))
+		return
+	}
+	hash := res.Hash
+	w.state.PutTx(store.TxRecord{Hash: hash, Kind: store.TxApprove, IDs: []uint64{id}, SentAt: time.Now(), SentBlock: w.head})
+	w.resume(store.TxApprove, []uint64{id})
+	fields := []zap.Field{zap.Uint64("id", id), zap.String("tx", hash.Hex())}
+	if req, ok := w.requests.get(id); ok {
+		w.policy.Record(w.policyRequest(req))
+		fields = append(fields, w.amountFields(req)...)
+	}
+	w.log.Info("approve sent", fields...)
+}
+
+// approveFailed drops, retries or escalates an approval depending on why it
+// failed.
+func (w *Watcher) approveFailed(ctx context.Context, ethClient requestClient, id uint64, cause error, msg string) {
+	action := actionFor(cause)
+	fields := []zap.Field{zap.Uint64("id", id), zap.String("action", action.String()), zap.Error(cause)}
+	switch action {
+	case actionDrop:
+		w.log.Info(msg, fields...)
+		w.refresh(ctx, ethClient, id)
+	case actionEscalate:
+		w.metrics.IncEscalations()
+		w.log.Error(msg, fields...)
+		w.approveRetry[id] = time.Now().Add(w.cfg.ReconcileInterval)
+	case actionDefer:
+		w.postpone(store.TxApprove, []uint64{id}, cause)
+		w.approveRetry[id] = time.Now()
+	default:
+		w.log.Warn(msg, fields...)
+		w.approveRetry[id] = time.Now().Add(execCooldown)
+	}
+}
+
+// retryApprovals re-attempts approvals that failed for a transient reason.
+// The policy is consulted again first, since it only counted approvals that
+// were sent.
+func (w *Watcher) retryApprovals(ctx context.Context, ethClient *client.EthClient) {
+	now := time.Now()
+	for id, until := range w.approveRetry {
+		if now.Before(until) {
+			continue
+		}
+		delete(w.approveRetry, id)
+		req, ok := w.requests.get(id)
+		if !ok {
+			continue
+		}
+		w.admit(ctx, ethClient, req)
+	}
+}
+
+// postpone puts ids in the deferral queue. Each id is counted once per
+// deferral, however many ticks it keeps waiting.
+func (w *Watcher) postpone(kind store.TxKind, ids []uint64, cause error) {
+	now := time.Now()
+	added := 0
+	for _, id := range ids {
+		if w.deferred.add(kind, id, now) {
+			w.metrics.IncDeferrals()
+			added++
+		}
+	}
+	w.metrics.SetDeferred(w.deferred.len())
+	if added > 0 {
+		w.log.Warn("fee over max tx fee, deferring", zap.String("kind", string(kind)), zap.Uint64s("ids", ids), zap.Error(cause))
+	}
+}
+
+// resume takes sent ids out of the deferral queue.
+func (w *Watcher) resume(kind store.TxKind, ids []uint64) {
+	now := time.Now()
+	for _, id := range ids {
+		if waited, ok := w.deferred.resolve(kind, id, now); ok {
+			w.log.Info("deferred tx sent", zap.String("kind", string(kind)), zap.Uint64("id", id), zap.Duration("waited", waited))
+		}
+	}
+	w.metrics.SetDeferred(w.deferred.len())
+}
+
+// batchFailed handles an executeBatch that failed or would revert. Its ids
+// are requeued unless the cause shows they are finished.
+func (w *Watcher) batchFailed(ctx context.Context, ethClient requestClient, batch []uint64, cause error, msg string) {
+	action := actionFor(cause)
+	fields := []zap.Field{zap.Uint64s("ids", batch), zap.String("action", action.String()), zap.Error(cause)}
+	switch action {
+	case actionDrop:
+		w.log.Info(msg, fields...)
+		for _, id := range batch {
+			w.refresh(ctx, ethClient, id)
+		}
+		return
+	case actionDefer:
+		// Left out of any cooldown so the next tick's batch retries them.
+		w.postpone(store.TxExecute, batch, cause)
+		return
+	case actionEscalate:
+		w.metrics.IncEscalations()
+		w.log.Error(msg, fields...)
+	default:
+		w.log.Warn(msg, fields...)
+	}
+	for _, id := range batch {
+		w.requeue(id, cause.Error())
+	}
+}
+
+// restore rebuilds the in-memory view from the last checkpoint: pending
+// requests become active again, those without a sent approval are queued for
+// a fresh policy evaluation, and recent executeBatch sends keep their
+// cooldown so a restart does not immediately resubmit them.
+func (w *Watcher) restore() {
+	for _, req := range w.state.Requests() {
+		if req.Status != statusPending {
+			continue
+		}
+		w.requests.track(req)
+		w.reevaluate(req.ID)
+	}
+	for _, tx := range w.state.Txs() {
+		if tx.Kind != store.TxExecute {
+			continue
+		}
+		for _, id := range tx.IDs {
+			w.execCooldownUntil[id] = tx.SentAt.Add(execCooldown)
+		}
+	}
+}
+
+// openState loads the checkpoint from DATA_DIR. Dry-run and observer
+// modes never send, so they keep their state in memory and leave the
+// checkpoint, spend ledger and nonces of the live daemon untouched.
+func (w *Watcher) openState() (*store.Store, error) {
+	switch w.cfg.Mode {
+	case config.ModeDryRun, config.ModeObserver:
+		w.log.Info("state kept in memory, DATA_DIR is not written", zap.String("mode", w.cfg.Mode))
+		return store.NewMemory(), nil
+	}
+	return store.Open(w.cfg.DataDir)
+}
+
+func (w *Watcher) checkNonces(ctx context.Context, ethClient *client.EthClient) {
+	gaps, err := ethClient.CheckNonces(ctx)
+	if err != nil {
+		w.log.Error("nonce check failed", zap.Error(err))
+		w.metrics.IncFailures()
+	}
+	for _, found := range gaps {
+		w.metrics.AddNonceGaps(len(found))
+	}
+}
+
+func (w *Watcher) checkpoint() {
+	if err := w.state.Checkpoint(); err != nil {
+		w.log.Error("checkpoint failed", zap.String("path", w.state.Path()), zap.Error(err))
+		w.metrics.IncFailures()
+	}
+}
+
+// updateRequest stores the latest known state of a tracked request and drops
+// it once the contract reports a final status.
+func (w *Watcher) updateRequest(req client.RequestState) {
+	if req.Status != statusPending {
+		if w.spendLimits != nil {
+			if req.Status == statusExecuted {
+				w.spendLimits.executed(req)
+			} else {
+				w.spendLimits.release(req.ID)
+			}
+		}
+		if asset, ok := w.assets[req.Token]; ok && req.Status == statusExecuted && req.Amount != nil {
+			if amount, err := strconv.ParseFloat(client.FormatUnits(req.Amount, asset.Decimals), 64); err == nil {
+				w.metrics.AddExecutedAmount(req.Token.Hex(), asset.Symbol, amount)
+			}
+		}
+		w.forget(req.ID)
+		fields := []zap.Field{zap.Uint64("id", req.ID), zap.Uint8("status", req.Status)}
+		w.log.Info("request finalized", append(fields, w.amountFields(req)...)...)
+		return
+	}
+	w.requests.track(req)
+	w.state.PutRequest(req)
+}
+
+func (w *Watcher) forget(id uint64) {
+	w.requests.remove(id)
+	delete(w.execCooldownUntil, id)
+	delete(w.approveRetry, id)
+	delete(w.held, id)
+	w.deferred.forget(id)
+	w.metrics.SetDeferred(w.deferred.len())
+	w.state.ForgetRequest(id)
+}
+
+// reconcile re-reads every tracked request from the contract to correct any
+// drift from missed or misapplied events.
+func (w *Watcher) reconcile(ctx context.Context, ethClient requestClient) {
+	w.lastReconcile = time.Now()
+	for _, id := range w.requests.ids() {
 		req, err := ethClient.GetRequest(ctx, id)
 		if err != nil {
 			w.log.Error("request fetch failed", zap.Uint64("id", id), zap.Error(err))
 			w.metrics.IncFailures()
 			continue
 		}
-		if req.Status != 0 {
-			delete(active, id)
-			delete(w.execCooldownUntil, id)
-			w.log.Info("request finalized", zap.Uint64("id", id), zap.Uint8("status", req.Status))
-			continue
+		if local, ok := w.requests.get(id); ok && !sameLifecycle(local, req) {
+			w.log.Warn("request drift corrected",
+				zap.Uint64("id", id),
+				zap.Uint64("approvals", req.Approvals),
+				zap.Uint64("local_approvals", local.Approvals),
+				zap.Uint8("status", req.Status),
+				zap.Uint8("local_status", local.Status),
+			)
+			w.metrics.IncReconcileDrift()
 		}
-		if until, ok := w.execCooldownUntil[id]; ok && time.Now().Before(until) {
+		w.updateRequest(req)
+	}
+}
+
+func sameLifecycle(a, b client.RequestState) bool {
+	return a.Status == b.Status &&
+		a.Approvals == b.Approvals &&
+		a.ApprovalsNeeded == b.ApprovalsNeeded &&
+		a.EarliestExec == b.EarliestExec &&
+		a.ExpiresAt == b.ExpiresAt
+}
+
+func (w *Watcher) buildReadyBatch(ctx context.Context, ethClient requestClient) []uint64 {
+	now, err := ethClient.ChainTime(ctx)
+	if err != nil {
+		w.log.Error("chain time fetch failed", zap.Error(err))
+		w.metrics.IncFailures()
+		return nil
+	}
+	batch := make([]uint64, 0, w.cfg.MaxBatch)
+	for _, id := range w.requests.ids() {
+		req, _ := w.requests.get(id)
+		if lapsed(req, now) {
+			if w.spendLimits != nil {
+				w.spendLimits.release(id)
+			}
+			w.forget(id)
+			w.log.Info("request lapsed", zap.Uint64("id", id), zap.Uint64("expires_at", req.ExpiresAt))
 			continue
 		}
-		if req.ApprovalsNeeded > 0 && req.Approvals < req.ApprovalsNeeded {
+		if until, ok := w.execCooldownUntil[id]; ok && time.Now().Before(until) {
 			continue
 		}
-		if now < req.EarliestExec {
+		if w.state.HasTx(store.TxExecute, id) {
 			continue
 		}
-		if req.ExpiresAt > 0 && now > req.ExpiresAt {
+		if !isReady(req, now) {
 			continue
 		}
 		batch = append(batch, id)
@@ -168,13 +861,128 @@ func (w *Watcher) buildReadyBatch(ctx context.Context, ethClient requestClient,
 	return batch
 }
 
-func (w *Watcher) policyAllows(req client.RequestState) bool {
-	if w.maxAmount != nil && req.Amount.Cmp(w.maxAmount) > 0 {
-		return false
+// reloadPolicy picks up edits to POLICY_FILE. A file that fails validation
+// is logged and the previous policy stays active.
+func (w *Watcher) reloadPolicy() {
+	if changed, size, err := w.recipients.Reload(); err != nil {
+		w.log.Error("denylist reload failed, keeping previous list", zap.String("path", w.cfg.RecipientDenylistFile), zap.Error(err))
+	} else if changed {
+		w.denylistLoaded(size)
 	}
-	if len(w.allowedTokens) == 0 {
-		return true
+	if w.policyFile == nil {
+		return
+	}
+	changed, err := w.policyFile.Reload()
+	if err != nil {
+		w.metrics.IncPolicyReloads("invalid")
+		w.log.Error("policy reload failed, keeping previous policy", zap.String("path", w.cfg.PolicyFile), zap.String("version", w.policyFile.Version()), zap.Error(err))
+		return
+	}
+	if changed {
+		w.metrics.IncPolicyReloads("ok")
+		w.policyLoaded()
+	}
+}
+
+func (w *Watcher) denylistLoaded(size int) {
+	w.metrics.SetDenylistSize(size)
+	w.log.Info("recipient denylist loaded", zap.String("path", w.cfg.RecipientDenylistFile), zap.Int("addresses", size))
+}
+
+func (w *Watcher) policyLoaded() {
+	version := w.policyFile.Version()
+	w.metrics.SetPolicyVersion(version)
+	w.log.Info("policy loaded", zap.String("path", w.cfg.PolicyFile), zap.String("version", version))
+}
+
+// lookupToken caches the decimals and symbol of token for policy limits in
+// whole units and for log output. Failures are logged and retried on the next
+// request for the token.
+func (w *Watcher) lookupToken(ctx context.Context, tokens tokenInfoSource, token common.Address) {
+	if _, ok := w.assets[token]; ok {
+		return
+	}
+	info, err := tokens.TokenInfo(ctx, token)
+	if err != nil {
+		w.log.Warn("token metadata lookup failed", zap.String("token", token.Hex()), zap.Error(err))
+		return
+	}
+	w.assets[token] = info
+}
+
+// lookupPrice fetches the USD price of token when the policy file gives it
+// a feed, and keeps it only if it passes the staleness and deviation checks.
+func (w *Watcher) lookupPrice(ctx context.Context, feeds priceSource, token common.Address) {
+	if w.policyFile == nil {
+		return
+	}
+	feed, maxAge, ok := w.policyFile.priceFeed(token)
+	if !ok {
+		delete(w.prices, token)
+		return
+	}
+	if maxAge == 0 {
+		maxAge = w.cfg.PriceMaxAge
+	}
+	quote, err := feeds.LatestPrice(ctx, feed)
+	if err == nil {
+		err = quote.Check(time.Now(), maxAge, w.cfg.PriceMaxDeviation)
+	}
+	if err != nil {
+		w.log.Warn("usd price rejected", zap.String("token", token.Hex()), zap.String("feed", feed.Hex()), zap.Error(err))
+		w.prices[token] = priceCheck{err: err}
+		return
+	}
+	w.prices[token] = priceCheck{quote: &quote}
+}
+
+// lookupRecipient checks whether to has contract code, for the recipient
+// screen. A failed lookup leaves the recipient unscreened.
+func (w *Watcher) lookupRecipient(ctx context.Context, codes codeSource, to common.Address) {
+	if !w.cfg.RecipientCodeCheck {
+		return
+	}
+	hasCode, err := codes.HasCode(ctx, to)
+	if err != nil {
+		w.log.Warn("recipient code lookup failed", zap.String("to", to.Hex()), zap.Error(err))
+		delete(w.recipientCode, to)
+		return
+	}
+	w.recipientCode[to] = hasCode
+}
+
+// amountFields logs a request's amount in base units and, when the token is
+// known, in whole tokens.
+func (w *Watcher) amountFields(req client.RequestState) []zap.Field {
+	fields := []zap.Field{zap.String("token", req.Token.Hex()), zap.String("amount", req.Amount.String())}
+	if asset, ok := w.assets[req.Token]; ok && req.Amount != nil {
+		fields = append(fields, zap.String("symbol", asset.Symbol), zap.String("amount_formatted", asset.Format(req.Amount)))
+	}
+	return fields
+}
+
+// evaluatePolicy runs the approve decision for req through the policy
+// engine and counts rejections by reason. Nothing is recorded until the
+// approval is sent.
+func (w *Watcher) evaluatePolicy(req client.RequestState) Decision {
+	d := w.policy.Decide(w.policyRequest(req))
+	if !d.Allow {
+		w.metrics.IncPolicyRejections(d.Reason)
+	}
+	return d
+}
+
+// policyRequest builds the policy input for req from the cached lookups.
+func (w *Watcher) policyRequest(req client.RequestState) PolicyRequest {
+	preq := PolicyRequest{ID: req.ID, Token: req.Token, To: req.To, Amount: req.Amount, CreatedBy: req.CreatedBy}
+	if asset, ok := w.assets[req.Token]; ok {
+		preq.Asset = &asset
+	}
+	if price, ok := w.prices[req.Token]; ok {
+		preq.Price, preq.PriceErr = price.quote, price.err
+	}
+	if hasCode, ok := w.recipientCode[req.To]; ok {
+		preq.ToHasCode = &hasCode
 	}
-	_, ok := w.allowedTokens[req.Token]
-	return ok
+	return preq
 }
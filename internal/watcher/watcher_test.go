@@ -3,21 +3,25 @@ package watcher
 import (
 	"context"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"base-treasury-guard/internal/client"
 	"base-treasury-guard/internal/config"
 	"base-treasury-guard/internal/metrics"
+	"base-treasury-guard/internal/store"
 
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
 )
 
 type fakeClient struct {
-	now uint64
-	req client.RequestState
-	err error
+	now  uint64
+	req  client.RequestState
+	reqs map[uint64]client.RequestState
+	err  error
 }
 
 func (f *fakeClient) ChainTime(ctx context.Context) (uint64, error) {
@@ -28,9 +32,25 @@ func (f *fakeClient) GetRequest(ctx context.Context, id uint64) (client.RequestS
 	if f.err != nil {
 		return client.RequestState{}, f.err
 	}
+	if req, ok := f.reqs[id]; ok {
+		return req, nil
+	}
 	return f.req, nil
 }
 
+type fakeHeads struct {
+	confirmed uint64
+	hashes    map[uint64]common.Hash
+}
+
+func (f *fakeHeads) ConfirmedBlock(ctx context.Context) (uint64, error) {
+	return f.confirmed, nil
+}
+
+func (f *fakeHeads) CanonicalHash(ctx context.Context, number uint64) (common.Hash, error) {
+	return f.hashes[number], nil
+}
+
 func TestCooldownPreventsResubmit(t *testing.T) {
 	cfg := config.Config{MaxBatch: 10}
 	w := New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
@@ -46,26 +66,53 @@ func TestCooldownPreventsResubmit(t *testing.T) {
 		Status:          0,
 	}
 	fc := &fakeClient{now: 10, req: req}
-	active := map[uint64]struct{}{1: {}}
+	w.requests.track(req)
 
 	w.execCooldownUntil[1] = time.Now().Add(30 * time.Second)
-	batch := w.buildReadyBatch(context.Background(), fc, active)
+	batch := w.buildReadyBatch(context.Background(), fc)
 	if len(batch) != 0 {
 		t.Fatalf("expected empty batch during cooldown")
 	}
 
 	w.execCooldownUntil[1] = time.Now().Add(-1 * time.Second)
-	batch = w.buildReadyBatch(context.Background(), fc, active)
+	batch = w.buildReadyBatch(context.Background(), fc)
 	if len(batch) != 1 || batch[0] != 1 {
 		t.Fatalf("expected batch to include id after cooldown")
 	}
 }
 
+func TestReconcileCorrectsDrift(t *testing.T) {
+	cfg := config.Config{MaxBatch: 10}
+	w := New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
+
+	local := client.RequestState{ID: 3, Amount: big.NewInt(1), ApprovalsNeeded: 2, EarliestExec: 1, ExpiresAt: 1000}
+	w.requests.track(local)
+
+	chain := local
+	chain.Approvals = 2
+	fc := &fakeClient{now: 10, req: chain}
+	if batch := w.buildReadyBatch(context.Background(), fc); len(batch) != 0 {
+		t.Fatalf("expected no batch before reconcile")
+	}
+
+	w.reconcile(context.Background(), fc)
+	batch := w.buildReadyBatch(context.Background(), fc)
+	if len(batch) != 1 || batch[0] != 3 {
+		t.Fatalf("expected reconciled request to be ready, got %v", batch)
+	}
+
+	fc.req.Status = statusCancelled
+	w.reconcile(context.Background(), fc)
+	if w.requests.has(3) {
+		t.Fatalf("expected cancelled request to be dropped")
+	}
+}
+
 func TestPolicyAllowsUnderMaxAmount(t *testing.T) {
 	cfg := config.Config{PolicyMaxAmount: "100"}
 	w := New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
 	req := client.RequestState{Amount: big.NewInt(100), Token: common.Address{}}
-	if !w.policyAllows(req) {
+	if !w.evaluatePolicy(req).Allow {
 		t.Fatalf("expected request under max amount to be allowed")
 	}
 }
@@ -76,12 +123,176 @@ func TestPolicyAllowlistEnforced(t *testing.T) {
 	w := New(cfg, zap.NewNop(), metrics.NewRegistry("test"))
 
 	bad := client.RequestState{Amount: big.NewInt(1), Token: common.HexToAddress("0x2222222222222222222222222222222222222222")}
-	if w.policyAllows(bad) {
+	if d := w.evaluatePolicy(bad); d.Allow || d.Reason != ReasonTokenNotAllowed {
 		t.Fatalf("expected non-allowlisted token to be rejected")
 	}
 
 	good := client.RequestState{Amount: big.NewInt(1), Token: allowed}
-	if !w.policyAllows(good) {
+	if !w.evaluatePolicy(good).Allow {
 		t.Fatalf("expected allowlisted token to be allowed")
 	}
 }
+
+// fakeScreen serves the lookups the policy needs. Each price lookup
+// returns the next error in priceErrs until they run out.
+type fakeScreen struct {
+	priceErrs []error
+}
+
+func (f *fakeScreen) TokenInfo(ctx context.Context, token common.Address) (client.TokenInfo, error) {
+	return client.TokenInfo{Symbol: "USDC", Decimals: 6}, nil
+}
+
+func (f *fakeScreen) LatestPrice(ctx context.Context, feed common.Address) (client.PriceQuote, error) {
+	if len(f.priceErrs) > 0 {
+		err := f.priceErrs[0]
+		f.priceErrs = f.priceErrs[1:]
+		return client.PriceQuote{}, err
+	}
+	return client.PriceQuote{Feed: feed, Decimals: 8, Answer: big.NewInt(1_00000000), UpdatedAt: time.Now()}, nil
+}
+
+func (f *fakeScreen) HasCode(ctx context.Context, account common.Address) (bool, error) {
+	return false, nil
+}
+
+func TestHeldRequestApprovedOncePriceRecovers(t *testing.T) {
+	token := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	feed := common.HexToAddress("0x7e860098F58bBFC8648a4311b374B1D669a2bc6B")
+	path := filepath.Join(t.TempDir(), "policy.json")
+	spec := `{"tokens": {"` + token.Hex() + `": {"max_amount": "1000 USD", "price_feed": "` + feed.Hex() + `"}}}`
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	w := New(config.Config{PolicyFile: path}, zap.NewNop(), metrics.NewRegistry("test"))
+	if _, err := w.policyFile.Reload(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	req := client.RequestState{ID: 9, Token: token, To: common.HexToAddress("0x05"), Amount: big.NewInt(500_000000)}
+	w.requests.track(req)
+
+	src := &fakeScreen{priceErrs: []error{client.ErrPriceUnavailable}}
+	if w.screen(context.Background(), src, req) {
+		t.Fatalf("expected request to fail closed without a price")
+	}
+	hold, ok := w.held[9]
+	if !ok || hold.attempts != 1 || !hold.until.After(time.Now()) {
+		t.Fatalf("expected request held for a later attempt, got %+v", hold)
+	}
+
+	if !w.screen(context.Background(), src, req) {
+		t.Fatalf("expected request allowed once the price is back")
+	}
+	if _, ok := w.held[9]; ok {
+		t.Fatalf("expected hold cleared after approval")
+	}
+
+	over := req
+	over.ID, over.Amount = 10, big.NewInt(1500_000000)
+	if w.screen(context.Background(), src, over) {
+		t.Fatalf("expected request over the usd limit to be rejected")
+	}
+	if _, ok := w.held[10]; ok {
+		t.Fatalf("expected a limit denial to be final")
+	}
+}
+
+func TestRestartRequeuesUnapprovedRequests(t *testing.T) {
+	dir := t.TempDir()
+	state, err := store.Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// Request 1 was persisted but the daemon stopped before approving it;
+	// request 2's approval went out.
+	state.PutRequest(client.RequestState{ID: 1, Amount: big.NewInt(1), ExpiresAt: 1000})
+	state.PutRequest(client.RequestState{ID: 2, Amount: big.NewInt(1), ExpiresAt: 1000})
+	state.PutTx(store.TxRecord{Hash: common.HexToHash("0x02"), Kind: store.TxApprove, IDs: []uint64{2}})
+	if err := state.Checkpoint(); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+
+	reopened, err := store.Open(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	w := New(config.Config{}, zap.NewNop(), metrics.NewRegistry("test"))
+	w.state = reopened
+	w.restore()
+
+	if !w.requests.has(1) || !w.requests.has(2) {
+		t.Fatalf("expected both pending requests tracked after restart")
+	}
+	if hold, ok := w.held[1]; !ok || time.Now().Before(hold.until) {
+		t.Fatalf("expected unapproved request queued for policy evaluation on the next tick")
+	}
+	if _, ok := w.held[2]; ok {
+		t.Fatalf("expected request with a sent approval not to be re-evaluated")
+	}
+	if !w.screen(context.Background(), &fakeScreen{}, client.RequestState{ID: 1, Amount: big.NewInt(1)}) {
+		t.Fatalf("expected restored request to pass policy")
+	}
+	if _, ok := w.held[1]; ok {
+		t.Fatalf("expected hold cleared once the request is screened")
+	}
+}
+
+func TestReorgedCancellationRequeuesApproval(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	pending := func(id uint64) client.RequestState {
+		return client.RequestState{ID: id, Amount: big.NewInt(1), ExpiresAt: 1000}
+	}
+	for id := uint64(1); id <= 2; id++ {
+		w.requests.track(pending(id))
+		cancelled := pending(id)
+		cancelled.Status = statusCancelled
+		w.updateRequest(cancelled)
+	}
+	w.state.PutTx(store.TxRecord{Hash: common.HexToHash("0x02"), Kind: store.TxApprove, IDs: []uint64{2}})
+
+	// The RequestCancelled logs were reorged out and both are pending again.
+	fc := &fakeClient{reqs: map[uint64]client.RequestState{1: pending(1), 2: pending(2)}}
+	w.refresh(context.Background(), fc, 1)
+	w.refresh(context.Background(), fc, 2)
+
+	if !w.requests.has(1) || !w.requests.has(2) {
+		t.Fatalf("expected reorged requests tracked again")
+	}
+	if hold, ok := w.held[1]; !ok || time.Now().Before(hold.until) {
+		t.Fatalf("expected unapproved request queued for approval on the next tick")
+	}
+	if _, ok := w.held[2]; ok {
+		t.Fatalf("expected request with a sent approval not to be re-evaluated")
+	}
+}
+
+func TestConfirmCreationsWaitsForDepthAndDropsReorged(t *testing.T) {
+	w := New(config.Config{MaxBatch: 10}, zap.NewNop(), metrics.NewRegistry("test"))
+	canonical := common.HexToHash("0xaa")
+	heads := &fakeHeads{confirmed: 100, hashes: map[uint64]common.Hash{90: canonical, 95: canonical}}
+
+	w.handleCreatedLog(1, client.EventMeta{BlockNumber: 90, BlockHash: canonical})
+	w.handleCreatedLog(2, client.EventMeta{BlockNumber: 95, BlockHash: common.HexToHash("0xbb")})
+	w.handleCreatedLog(3, client.EventMeta{BlockNumber: 101, BlockHash: canonical})
+	w.handleCreatedLog(4, client.EventMeta{BlockNumber: 99, BlockHash: canonical})
+	w.handleCreatedLog(4, client.EventMeta{BlockNumber: 99, BlockHash: canonical, Removed: true})
+
+	ready := w.confirmCreations(context.Background(), heads)
+	if len(ready) != 1 || ready[0] != 1 {
+		t.Fatalf("expected only id 1 to confirm, got %v", ready)
+	}
+	if _, ok := w.unconfirmed[3]; !ok {
+		t.Fatalf("expected id 3 to wait for confirmations")
+	}
+	if _, ok := w.unconfirmed[2]; ok {
+		t.Fatalf("expected reorged id 2 to be dropped")
+	}
+	if _, ok := w.unconfirmed[4]; ok {
+		t.Fatalf("expected removed id 4 to be dropped")
+	}
+
+	w.advanceCheckpoint(120)
+	if got := w.state.LastBlock(); got != 100 {
+		t.Fatalf("expected checkpoint held at 100 by pending creation, got %d", got)
+	}
+}